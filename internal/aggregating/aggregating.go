@@ -3,126 +3,463 @@ package aggregating
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 	"io"
 	"net/http"
-	"encoding/xml"
-	"html"
+	"net/url"
 	"github.com/google/uuid"
 	"github.com/MedrekIT/gator/internal/config"
 	"github.com/MedrekIT/gator/internal/database"
+	"github.com/MedrekIT/gator/internal/deduplicating"
+	"github.com/MedrekIT/gator/internal/filtering"
+	"github.com/MedrekIT/gator/internal/rewriting"
 )
 
+// recentFingerprints limits how many latest posts are compared against new ones while looking for duplicates
+const recentFingerprints = 2000
+
 type RSSFeed struct {
 	Channel struct {
-		Title       string    `xml:"title"`
-		Link        string    `xml:"link"`
-		Description string    `xml:"description"`
-		Item        []RSSItem `xml:"item"`
+		Title         string     `xml:"title"`
+		AtomLinks     []atomLink `xml:"http://www.w3.org/2005/Atom link"`
+		Link          string     `xml:"link"`
+		Description   string     `xml:"description"`
+		Language      string     `xml:"language"`
+		Image         RSSImage   `xml:"image"`
+		Generator     string     `xml:"generator"`
+		LastBuildDate string     `xml:"lastBuildDate"`
+		Base          string     `xml:"http://www.w3.org/XML/1998/namespace base,attr"`
+		Item          []RSSItem  `xml:"item"`
 	} `xml:"channel"`
 }
 
+type RSSImage struct {
+	URL  string `xml:"url"`
+	Href string `xml:"href,attr"`
+}
+
 type RSSItem struct {
-	Title       string `xml:"title"`
-	Link        string `xml:"link"`
-	Description string `xml:"description"`
-	PubDate     string `xml:"pubDate"`
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Description string   `xml:"description"`
+	Content     string   `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	PubDate     string   `xml:"pubDate"`
+	Date        string   `xml:"http://purl.org/dc/elements/1.1/ date"`
+	GUID        string   `xml:"guid"`
+	Author      string   `xml:"author"`
+	Creators    []string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Categories  []string `xml:"category"`
+	Subjects    []string `xml:"http://purl.org/dc/elements/1.1/ subject"`
+	Authors     []string `xml:"-"`
+	Base        string   `xml:"http://www.w3.org/XML/1998/namespace base,attr"`
+	OrigLink    string   `xml:"http://rssnamespace.org/feedburner/ext/1.0 origLink"`
+}
+
+// Result summarizes a single feed fetch
+type Result struct {
+	Feed database.Feed
+	Status string
+	NewPosts int
+	UpdatedPosts int
+	Duration time.Duration
+	Err error
 }
 
+// ScrapeFeeds fetches the followed feed which waits the longest for its turn, feeds nobody follows are skipped
 func ScrapeFeeds(ctx context.Context, s *config.State) error {
 	feed, err := s.Db.GetNextFeedToFetch(ctx)
 	if err != nil {
-		if strings.Contains(err.Error(), "sql: no rows in result set") {
-			return fmt.Errorf("nothing to browse, database is empty!\n")
+		if errors.Is(err, database.ErrNotFound) {
+			return fmt.Errorf("nothing to fetch, nobody follows any feed!\n")
 		}
 		return fmt.Errorf("error while getting feeds to fetch from the database - %w\n", err)
 	}
 
-	newMarkFeedParams := database.MarkFeedFetchedParams{
+	return ScrapeFeed(ctx, s, feed).Err
+}
+
+// ScrapeFeed fetches given feed and saves its posts, all errors are reported in the result
+func ScrapeFeed(ctx context.Context, s *config.State, feed database.Feed) Result {
+	start := time.Now()
+	res := Result{
+		Feed: feed,
+	}
+	res.Err = scrapeFeed(ctx, s, feed, &res)
+	res.Duration = time.Since(start)
+
+	return res
+}
+
+func scrapeFeed(ctx context.Context, s *config.State, feed database.Feed, res *Result) error {
+	// the attempt lets other feeds take their turn when this one keeps failing, it's marked as fetched only once its posts are saved
+	newMarkAttemptParams := database.MarkFeedAttemptedParams{
 		ID: feed.ID,
-		UpdatedAt: time.Now(),
+		LastAttemptedAt: sql.NullTime{
+			Time: time.Now(),
+			Valid: true,
+		},
 	}
-	_, err = s.Db.MarkFeedFetched(ctx, newMarkFeedParams)
+	err := s.Db.MarkFeedAttempted(ctx, newMarkAttemptParams)
 	if err != nil {
-		return fmt.Errorf("error while marking feed as fetched in the database - %w\n", err)
+		return fmt.Errorf("error while marking feed fetch attempt in the database - %w\n", err)
+	}
+
+	fetchedFeed, status, err := FetchFeed(ctx, feed.Url)
+	res.Status = status
+	if err != nil {
+		return err
 	}
 
-	fetchedFeed, err := fetchFeed(ctx, feed.Url)
+	return savePosts(ctx, s, feed, fetchedFeed, res)
+}
+
+// SavePosts saves posts of a feed which has already been fetched, e.g. while validating it, and marks it as fetched
+func SavePosts(ctx context.Context, s *config.State, feed database.Feed, fetchedFeed *RSSFeed) Result {
+	start := time.Now()
+	res := Result{
+		Feed: feed,
+	}
+	res.Err = savePosts(ctx, s, feed, fetchedFeed, &res)
+	res.Duration = time.Since(start)
+
+	return res
+}
+
+// savePosts rewrites and filters posts first, as following redirects may take a while, then saves them in a single transaction
+// together with feed metadata, so the feed is marked as fetched only when all of its posts have been saved,
+// pruned links, saved posts and stories are looked up in the same transaction, so they can't change before the insert
+func savePosts(ctx context.Context, s *config.State, feed database.Feed, fetchedFeed *RSSFeed, res *Result) error {
+	filters, err := s.Db.GetGlobalDropFilters(ctx)
+	if err != nil {
+		return fmt.Errorf("error while getting filters from the database - %w\n", err)
+	}
+	rules, err := filtering.FromDatabase(filters)
 	if err != nil {
 		return err
 	}
 
+	rewriter := rewriting.New(s.Conf.Rewrite.For(feed.Url))
+	channelBase := rewriting.ResolveBase(feed.Url, fetchedFeed.Channel.Link, fetchedFeed.Channel.Base)
+
+	var items []RSSItem
 	for _, it := range fetchedFeed.Channel.Item {
-		publishedAt := sql.NullTime{}
-		if t, err := time.Parse(time.RFC1123Z, it.PubDate); err == nil {
-			publishedAt = sql.NullTime{
-				Time:  t,
-				Valid: true,
-			}
+		if it.Link == "" && (strings.HasPrefix(it.GUID, "http://") || strings.HasPrefix(it.GUID, "https://")) {
+			it.Link = it.GUID
+		}
+		rewritten := rewriter.Rewrite(ctx, rewriting.Item{
+			Title: it.Title,
+			Link: it.Link,
+			OrigLink: it.OrigLink,
+			Base: rewriting.ResolveBase(channelBase, it.Base),
+		})
+		it.Title = rewritten.Title
+		it.Link = rewritten.Link
+		if it.Link == "" {
+			continue
 		}
 
-		newPostParams := database.CreatePostParams{
-			ID: uuid.New().String(),
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
+		item := filtering.Item{
+			Feed: feed.Name,
 			Title: it.Title,
-			Url: it.Link,
-			Description: sql.NullString{
-				String: it.Description,
-				Valid: true,
-			},
-			PublishedAt: publishedAt,
-			FeedID: feed.ID,
-		}
-		_, err = s.Db.CreatePost(ctx, newPostParams)
+			Description: it.Description,
+			Author: strings.Join(it.Authors, ", "),
+			Categories: it.Categories,
+			URL: it.Link,
+		}
+		if rules.Matches(item) {
+			continue
+		}
+		items = append(items, it)
+	}
+
+	err = s.Db.InTx(ctx, func(db *database.Store) error {
+		if err := updateMetadata(ctx, db, feed, fetchedFeed); err != nil {
+			return err
+		}
+		stories, err := loadStories(ctx, db, feed.ID)
 		if err != nil {
-			if strings.Contains(err.Error(), "UNIQUE constraint failed: posts.url") {
-				continue
+			return err
+		}
+		for _, it := range items {
+			if err := savePost(ctx, db, feed, it, stories, res); err != nil {
+				return err
 			}
+		}
+
+		newMarkFeedParams := database.MarkFeedFetchedParams{
+			ID: feed.ID,
+			UpdatedAt: time.Now(),
+		}
+		_, err = db.MarkFeedFetched(ctx, newMarkFeedParams)
+		if err != nil {
+			return fmt.Errorf("error while marking feed as fetched in the database - %w\n", err)
+		}
+		return nil
+	})
+	if err != nil {
+		// the transaction has been rolled back, so nothing was saved
+		res.NewPosts = 0
+		res.UpdatedPosts = 0
+		return err
+	}
+	return nil
+}
+
+// savePost saves a new post or updates the one saved before, counting it in the result
+func savePost(ctx context.Context, db *database.Store, feed database.Feed, it RSSItem, stories *deduplicating.Index, res *Result) error {
+	pruned, err := db.IsPostPruned(ctx, it.Link)
+	if err != nil {
+		return fmt.Errorf("error while getting post from the database - %w\n", err)
+	}
+	if pruned != 0 {
+		return nil
+	}
+
+	authors := strings.Join(it.Authors, ", ")
+	publishedAt := sql.NullTime{}
+	if t, ok := parseDate(it.PubDate); ok {
+		publishedAt = sql.NullTime{
+			Time:  t,
+			Valid: true,
+		}
+	}
+
+	postID := uuid.New().String()
+	storyID := postID
+	fingerprint := sql.NullInt64{}
+	if fp, ok := deduplicating.Fingerprint(it.Title, it.Description); ok {
+		fingerprint = sql.NullInt64{
+			Int64: int64(fp),
+			Valid: true,
+		}
+		if id, ok := stories.Find(fp); ok {
+			storyID = id
+		}
+	}
+
+	newPostParams := database.CreatePostParams{
+		ID: postID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Title: it.Title,
+		Url: it.Link,
+		Description: sql.NullString{
+			String: it.Description,
+			Valid: true,
+		},
+		PublishedAt: publishedAt,
+		FeedID: feed.ID,
+		StoryID: storyID,
+		Fingerprint: fingerprint,
+		Authors: authors,
+		Content: nullString(it.Content),
+	}
+	post, err := db.CreatePost(ctx, newPostParams)
+	if err != nil {
+		if !errors.Is(err, database.ErrDuplicatePost) {
 			return fmt.Errorf("error while aggregating feed posts - %w\n", err)
 		}
+		// the same article was already saved, from this feed or another one which only becomes its new source
+		post, err = db.GetPostByURL(ctx, it.Link)
+		if err != nil {
+			return fmt.Errorf("error while getting post from the database - %w\n", err)
+		}
+		if post.FeedID == feed.ID && (post.Title != it.Title || post.Description.String != it.Description || post.Authors != authors || post.Content.String != it.Content) {
+			newUpdatePostParams := database.UpdatePostParams{
+				ID: post.ID,
+				UpdatedAt: time.Now(),
+				Title: it.Title,
+				Description: newPostParams.Description,
+				PublishedAt: publishedAt,
+				Authors: authors,
+				Content: newPostParams.Content,
+			}
+			err = db.UpdatePost(ctx, newUpdatePostParams)
+			if err != nil {
+				return fmt.Errorf("error while updating post in the database - %w\n", err)
+			}
+			if err = saveCategories(ctx, db, post.ID, it.Categories); err != nil {
+				return err
+			}
+			res.UpdatedPosts++
+		}
+	} else {
+		if err = saveCategories(ctx, db, post.ID, it.Categories); err != nil {
+			return err
+		}
+		res.NewPosts++
+	}
+
+	newPostSourceParams := database.AddPostSourceParams{
+		PostID: post.ID,
+		FeedID: feed.ID,
+		CreatedAt: time.Now(),
+	}
+	err = db.AddPostSource(ctx, newPostSourceParams)
+	if err != nil {
+		return fmt.Errorf("error while adding post source to the database - %w\n", err)
 	}
+	// the feed is a source of the story now, so its later posts aren't grouped into it
+	stories.Remove(post.StoryID)
 	return nil
 }
 
-func fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
+// saveCategories replaces categories of the post with given ones
+func saveCategories(ctx context.Context, db *database.Store, postID string, categories []string) error {
+	err := db.DeletePostCategories(ctx, postID)
 	if err != nil {
-		return &RSSFeed{}, fmt.Errorf("error while creating request - %w\n", err)
+		return fmt.Errorf("error while removing post categories from the database - %w\n", err)
 	}
 
-	req.Header.Set("user-agent", "gator")
-	req.Header.Set("content-type", "application/xml")
+	for _, category := range categories {
+		newPostCategoryParams := database.AddPostCategoryParams{
+			PostID: postID,
+			Category: category,
+		}
+		err = db.AddPostCategory(ctx, newPostCategoryParams)
+		if err != nil {
+			return fmt.Errorf("error while adding post category to the database - %w\n", err)
+		}
+	}
+	return nil
+}
 
-	client := &http.Client{}
-	res, err := client.Do(req)
+// updateMetadata refreshes channel details kept alongside the feed, site link is resolved against the feed URL and missing images fall back to site's favicon
+func updateMetadata(ctx context.Context, db *database.Store, feed database.Feed, fetchedFeed *RSSFeed) error {
+	channel := fetchedFeed.Channel
+	siteLink := ""
+	if channel.Link != "" {
+		siteLink = rewriting.ResolveBase(feed.Url, channel.Link)
+	}
+
+	imageURL := channel.Image.URL
+	if imageURL == "" {
+		imageURL = channel.Image.Href
+	}
+	if imageURL != "" {
+		imageURL = rewriting.ResolveBase(feed.Url, imageURL)
+	} else if u, err := url.Parse(rewriting.ResolveBase(feed.Url, siteLink)); err == nil && u.Host != "" {
+		imageURL = u.Scheme + "://" + u.Host + "/favicon.ico"
+	}
+
+	lastBuildDate := sql.NullTime{}
+	if t, ok := parseDate(channel.LastBuildDate); ok {
+		lastBuildDate = sql.NullTime{
+			Time: t,
+			Valid: true,
+		}
+	}
+
+	newUpdateFeedParams := database.UpdateFeedMetadataParams{
+		ID: feed.ID,
+		UpdatedAt: time.Now(),
+		SiteLink: nullString(siteLink),
+		Description: nullString(strings.TrimSpace(channel.Description)),
+		Language: nullString(strings.TrimSpace(channel.Language)),
+		ImageUrl: nullString(imageURL),
+		Generator: nullString(strings.TrimSpace(channel.Generator)),
+		LastBuildDate: lastBuildDate,
+	}
+	err := db.UpdateFeedMetadata(ctx, newUpdateFeedParams)
 	if err != nil {
-		return &RSSFeed{}, fmt.Errorf("error while fetching response - %w\n", err)
+		return fmt.Errorf("error while updating feed metadata in the database - %w\n", err)
 	}
 
-	if res.StatusCode > 299 {
-		return &RSSFeed{}, fmt.Errorf("response failed with status - %s\n", res.Status)
+	return nil
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{
+		String: value,
+		Valid: value != "",
 	}
-	defer res.Body.Close()
+}
+
+// loadStories indexes fingerprints of recently saved posts, which new posts of the feed may turn out to be duplicates of,
+// stories the feed is already a source of are left out, as similar posts of a single feed are separate articles
+func loadStories(ctx context.Context, db *database.Store, feedID string) (*deduplicating.Index, error) {
+	newGetRecentFingerprintsParams := database.GetRecentFingerprintsParams{
+		FeedID: feedID,
+		Limit: recentFingerprints,
+	}
+	fingerprints, err := db.GetRecentFingerprints(ctx, newGetRecentFingerprintsParams)
+	if err != nil {
+		return nil, fmt.Errorf("error while getting posts fingerprints from the database - %w\n", err)
+	}
+
+	stories := &deduplicating.Index{}
+	for _, fp := range fingerprints {
+		stories.Add(fp.StoryID, uint64(fp.Fingerprint.Int64))
+	}
+	return stories, nil
+}
+
+// response keeps details of the HTTP exchange, which are reported by the check command
+type response struct {
+	body []byte
+	status string
+	header http.Header
+	redirects []string
+	finalURL string
+}
+
+// FetchFeed downloads and parses the feed, returning HTTP status of the response as well
+func FetchFeed(ctx context.Context, feedURL string) (*RSSFeed, string, error) {
+	res, err := download(ctx, feedURL)
+	if err != nil {
+		return &RSSFeed{}, res.status, err
+	}
+
+	data, _, err := parseFeed(res.body)
+	if err != nil {
+		return &RSSFeed{}, res.status, err
+	}
+
+	return data, res.status, nil
+}
+
+func download(ctx context.Context, feedURL string) (response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
+	if err != nil {
+		return response{}, fmt.Errorf("error while creating request - %w\n", err)
+	}
+
+	req.Header.Set("user-agent", "gator")
+	req.Header.Set("accept", "application/rss+xml, application/atom+xml, application/rdf+xml, application/xml;q=0.9, text/xml;q=0.8")
 
-	dataBytes, err := io.ReadAll(res.Body)
+	var redirects []string
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return fmt.Errorf("stopped after 10 redirects")
+			}
+			redirects = append(redirects, fmt.Sprintf("%s -> %s", req.Response.Status, req.URL))
+			return nil
+		},
+	}
+	res, err := client.Do(req)
 	if err != nil {
-		return &RSSFeed{}, fmt.Errorf("error while reading data from body - %w\n", err)
+		return response{redirects: redirects}, fmt.Errorf("error while fetching response - %w\n", err)
 	}
+	defer res.Body.Close()
 
-	var data RSSFeed
-	if err := xml.Unmarshal(dataBytes, &data); err != nil {
-		return &RSSFeed{}, fmt.Errorf("error while decoding response body - %w\n", err)
+	r := response{
+		status: res.Status,
+		header: res.Header,
+		redirects: redirects,
+		finalURL: res.Request.URL.String(),
+	}
+	if res.StatusCode > 299 {
+		return r, fmt.Errorf("response failed with status - %s\n", res.Status)
 	}
 
-	data.Channel.Title = html.UnescapeString(data.Channel.Title)
-	data.Channel.Description = html.UnescapeString(data.Channel.Description)
-	for i, it := range data.Channel.Item {
-		data.Channel.Item[i].Title = html.UnescapeString(it.Title)
-		data.Channel.Item[i].Description = html.UnescapeString(it.Description)
+	r.body, err = io.ReadAll(res.Body)
+	if err != nil {
+		return r, fmt.Errorf("error while reading data from body - %w\n", err)
 	}
 
-	return &data, nil
+	return r, nil
 }
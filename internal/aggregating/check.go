@@ -0,0 +1,96 @@
+package aggregating
+
+import (
+	"context"
+	"mime"
+	"time"
+)
+
+// Report describes how a feed would be fetched and parsed, without saving anything
+type Report struct {
+	URL string
+	FinalURL string
+	Redirects []string
+	Status string
+	ContentType string
+	Format string
+	Encoding string
+	HeaderEncoding string
+	ETag string
+	LastModified string
+	CacheControl string
+	Expires string
+	Title string
+	Link string
+	Items int
+	DatesParsed int
+	MissingLinks int
+	MissingGUIDs int
+	Preview []PreviewItem
+}
+
+type PreviewItem struct {
+	Title string
+	Link string
+	PublishedAt time.Time
+	DateParsed bool
+}
+
+// Check runs full fetch-and-parse path against given URL, on failure the report holds everything gathered before the error
+func Check(ctx context.Context, feedURL string, previewSize int) (Report, error) {
+	report := Report{
+		URL: feedURL,
+	}
+
+	res, err := download(ctx, feedURL)
+	report.Redirects = res.redirects
+	report.Status = res.status
+	report.FinalURL = res.finalURL
+	if res.header != nil {
+		report.ContentType = res.header.Get("content-type")
+		report.ETag = res.header.Get("etag")
+		report.LastModified = res.header.Get("last-modified")
+		report.CacheControl = res.header.Get("cache-control")
+		report.Expires = res.header.Get("expires")
+		if _, params, err := mime.ParseMediaType(report.ContentType); err == nil {
+			report.HeaderEncoding = params["charset"]
+		}
+	}
+	if err != nil {
+		return report, err
+	}
+
+	report.Encoding = declaredEncoding(res.body)
+	feed, format, err := parseFeed(res.body)
+	report.Format = format
+	if err != nil {
+		return report, err
+	}
+
+	report.Title = feed.Channel.Title
+	report.Link = feed.Channel.Link
+	report.Items = len(feed.Channel.Item)
+	for i, it := range feed.Channel.Item {
+		publishedAt, ok := parseDate(it.PubDate)
+		if ok {
+			report.DatesParsed++
+		}
+		if it.Link == "" && it.OrigLink == "" {
+			report.MissingLinks++
+		}
+		if it.GUID == "" {
+			report.MissingGUIDs++
+		}
+
+		if i < previewSize {
+			report.Preview = append(report.Preview, PreviewItem{
+				Title: it.Title,
+				Link: it.Link,
+				PublishedAt: publishedAt,
+				DateParsed: ok,
+			})
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,327 @@
+package aggregating
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	FormatRSS = "RSS 2.0"
+	FormatAtom = "Atom"
+	FormatRDF = "RSS 1.0 (RDF)"
+)
+
+var encodingRegexp = regexp.MustCompile(`^<\?xml[^>]*encoding=["']([^"']+)["']`)
+
+// authorEmailRegexp matches RSS author given as "email (Name)"
+var authorEmailRegexp = regexp.MustCompile(`^\S+@\S+\s+\((.+)\)$`)
+
+// dateLayouts lists date formats found in the wild, RFC 822 variants for RSS and RFC 3339 for Atom and Dublin Core
+var dateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"Mon, 2 Jan 2006 15:04 -0700",
+	"Mon, 2 Jan 2006 15:04 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 MST",
+	time.RFC822Z,
+	time.RFC822,
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// windows1252 maps bytes 0x80-0x9F which differ from ISO-8859-1
+var windows1252 = map[byte]rune{
+	0x80: '€', 0x82: '‚', 0x83: 'ƒ', 0x84: '„', 0x85: '…', 0x86: '†', 0x87: '‡', 0x88: 'ˆ',
+	0x89: '‰', 0x8A: 'Š', 0x8B: '‹', 0x8C: 'Œ', 0x8E: 'Ž', 0x91: '‘', 0x92: '’', 0x93: '“',
+	0x94: '”', 0x95: '•', 0x96: '–', 0x97: '—', 0x98: '˜', 0x99: '™', 0x9A: 'š', 0x9B: '›',
+	0x9C: 'œ', 0x9E: 'ž', 0x9F: 'Ÿ',
+}
+
+type atomFeed struct {
+	Title     string      `xml:"title"`
+	Subtitle  string      `xml:"subtitle"`
+	Links     []atomLink  `xml:"link"`
+	Icon      string      `xml:"icon"`
+	Logo      string      `xml:"logo"`
+	Generator string      `xml:"generator"`
+	Updated   string      `xml:"updated"`
+	Authors   []string    `xml:"author>name"`
+	Lang      string      `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	Base      string      `xml:"http://www.w3.org/XML/1998/namespace base,attr"`
+	Entries   []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	Links      []atomLink     `xml:"link"`
+	ID         string         `xml:"id"`
+	Summary    string         `xml:"summary"`
+	Content    string         `xml:"content"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Authors    []string       `xml:"author>name"`
+	Categories []atomCategory `xml:"category"`
+	Base       string         `xml:"http://www.w3.org/XML/1998/namespace base,attr"`
+}
+
+type atomCategory struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr"`
+}
+
+type rdfFeed struct {
+	Channel struct {
+		Title       string `xml:"title"`
+		Link        string `xml:"link"`
+		Description string `xml:"description"`
+		Language    string `xml:"http://purl.org/dc/elements/1.1/ language"`
+		Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	} `xml:"channel"`
+	Image struct {
+		URL string `xml:"url"`
+	} `xml:"image"`
+	Items []RSSItem `xml:"item"`
+}
+
+// parseFeed detects format of the document and decodes RSS 2.0, RSS 1.0 and Atom feeds into RSSFeed
+func parseFeed(data []byte) (*RSSFeed, string, error) {
+	format, err := detectFormat(data)
+	if err != nil {
+		return &RSSFeed{}, "", err
+	}
+
+	var feed RSSFeed
+	switch format {
+	case FormatRSS:
+		err = decodeXML(data, &feed)
+	case FormatRDF:
+		var rdf rdfFeed
+		err = decodeXML(data, &rdf)
+		feed.Channel.Title = rdf.Channel.Title
+		feed.Channel.Link = rdf.Channel.Link
+		feed.Channel.Description = rdf.Channel.Description
+		feed.Channel.Language = rdf.Channel.Language
+		feed.Channel.LastBuildDate = rdf.Channel.Date
+		feed.Channel.Image.URL = rdf.Image.URL
+		feed.Channel.Item = rdf.Items
+	case FormatAtom:
+		var atom atomFeed
+		err = decodeXML(data, &atom)
+		feed = atom.toRSS()
+	}
+	if err != nil {
+		return &RSSFeed{}, format, fmt.Errorf("error while decoding response body - %w\n", err)
+	}
+
+	feed.Channel.Title = html.UnescapeString(strings.TrimSpace(feed.Channel.Title))
+	feed.Channel.Description = html.UnescapeString(feed.Channel.Description)
+	for i, it := range feed.Channel.Item {
+		feed.Channel.Item[i].Title = html.UnescapeString(strings.TrimSpace(it.Title))
+		feed.Channel.Item[i].Description = html.UnescapeString(it.Description)
+		feed.Channel.Item[i].Content = html.UnescapeString(it.Content)
+		if it.PubDate == "" {
+			feed.Channel.Item[i].PubDate = it.Date
+		}
+		feed.Channel.Item[i].Authors = itemAuthors(it)
+		feed.Channel.Item[i].Categories = uniqueValues(append(it.Categories, it.Subjects...))
+	}
+
+	return &feed, format, nil
+}
+
+// parseDate tries every known layout, zero time with false is returned for unknown formats
+func parseDate(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false
+	}
+
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// declaredEncoding returns encoding from XML declaration, documents without one are UTF-8
+func declaredEncoding(data []byte) string {
+	if m := encodingRegexp.FindSubmatch(bytes.TrimSpace(data)); m != nil {
+		return strings.ToLower(string(m[1]))
+	}
+
+	return "utf-8"
+}
+
+func detectFormat(data []byte) (string, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	d.CharsetReader = charsetReader
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return "", fmt.Errorf("response is not a valid XML document - %w\n", err)
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(start.Name.Local) {
+		case "rss":
+			return FormatRSS, nil
+		case "feed":
+			return FormatAtom, nil
+		case "rdf":
+			return FormatRDF, nil
+		}
+		return "", fmt.Errorf("response is not a feed, found <%s> document\n", start.Name.Local)
+	}
+}
+
+func decodeXML(data []byte, v any) error {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	d.CharsetReader = charsetReader
+	d.Strict = false
+	d.Entity = xml.HTMLEntity
+
+	return d.Decode(v)
+}
+
+// charsetReader supports single-byte encodings still used by older feeds, everything else has to be UTF-8
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "utf-8", "utf8", "us-ascii", "ascii":
+		return input, nil
+	case "iso-8859-1", "iso8859-1", "latin1", "latin-1", "windows-1252", "cp1252":
+		data, err := io.ReadAll(input)
+		if err != nil {
+			return nil, err
+		}
+
+		buf := make([]byte, 0, len(data))
+		for _, b := range data {
+			r := rune(b)
+			if special, ok := windows1252[b]; ok {
+				r = special
+			}
+			buf = utf8.AppendRune(buf, r)
+		}
+		return bytes.NewReader(buf), nil
+	}
+
+	return nil, fmt.Errorf("unsupported encoding \"%s\"", charset)
+}
+
+func (a atomFeed) toRSS() RSSFeed {
+	var feed RSSFeed
+	feed.Channel.Title = a.Title
+	feed.Channel.Link = alternateLink(a.Links)
+	feed.Channel.Description = a.Subtitle
+	feed.Channel.Language = a.Lang
+	feed.Channel.Generator = a.Generator
+	feed.Channel.LastBuildDate = a.Updated
+	feed.Channel.Image.URL = a.Logo
+	if a.Logo == "" {
+		feed.Channel.Image.URL = a.Icon
+	}
+	feed.Channel.Base = a.Base
+
+	for _, e := range a.Entries {
+		// content is kept apart only when there's a summary to serve as description
+		description, content := e.Summary, e.Content
+		if description == "" {
+			description, content = e.Content, ""
+		}
+		published := e.Published
+		if published == "" {
+			published = e.Updated
+		}
+
+		// entries without authors inherit authors of the whole feed
+		authors := e.Authors
+		if len(authors) == 0 {
+			authors = a.Authors
+		}
+		var categories []string
+		for _, c := range e.Categories {
+			if c.Term != "" {
+				categories = append(categories, c.Term)
+			} else {
+				categories = append(categories, c.Label)
+			}
+		}
+
+		feed.Channel.Item = append(feed.Channel.Item, RSSItem{
+			Title: e.Title,
+			Link: alternateLink(e.Links),
+			Description: description,
+			Content: content,
+			PubDate: published,
+			Creators: authors,
+			Categories: categories,
+			GUID: e.ID,
+			Base: e.Base,
+		})
+	}
+
+	return feed
+}
+
+func alternateLink(links []atomLink) string {
+	for _, l := range links {
+		if l.Rel == "" || l.Rel == "alternate" {
+			return l.Href
+		}
+	}
+	if len(links) != 0 {
+		return links[0].Href
+	}
+
+	return ""
+}
+
+// itemAuthors merges RSS author and Dublin Core creators, RSS authors are e-mail addresses often followed by the name in parentheses
+func itemAuthors(it RSSItem) []string {
+	var authors []string
+	if author := strings.TrimSpace(it.Author); author != "" {
+		if m := authorEmailRegexp.FindStringSubmatch(author); m != nil {
+			author = m[1]
+		}
+		authors = append(authors, author)
+	}
+
+	return uniqueValues(append(authors, it.Creators...))
+}
+
+// uniqueValues trims and unescapes values, dropping empty and repeated ones
+func uniqueValues(values []string) []string {
+	var unique []string
+	for _, v := range values {
+		v = html.UnescapeString(strings.TrimSpace(v))
+		if v != "" && !slices.Contains(unique, v) {
+			unique = append(unique, v)
+		}
+	}
+
+	return unique
+}
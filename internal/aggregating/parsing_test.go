@@ -0,0 +1,349 @@
+package aggregating
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseFeed(t *testing.T) {
+	cases := []struct {
+		name        string
+		data        string
+		format      string
+		title       string
+		link        string
+		description string
+		items       []RSSItem
+	}{
+		{
+			name: "rss",
+			data: `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+<channel>
+	<title> Example &amp; Co </title>
+	<link>https://example.com/</link>
+	<description>News &amp;amp; notes</description>
+	<item>
+		<title>First &lt;post&gt;</title>
+		<link>https://example.com/1</link>
+		<description>&lt;p&gt;Hello&lt;/p&gt;</description>
+		<content:encoded><![CDATA[<p>Hello &amp; welcome</p>]]></content:encoded>
+		<pubDate>Mon, 02 Jan 2006 15:04:05 -0700</pubDate>
+		<guid>post-1</guid>
+	</item>
+	<item>
+		<title>Second</title>
+		<link>https://example.com/2</link>
+		<dc:date>2006-01-03T10:00:00Z</dc:date>
+	</item>
+</channel>
+</rss>`,
+			format:      FormatRSS,
+			title:       "Example & Co",
+			link:        "https://example.com/",
+			description: "News & notes",
+			items: []RSSItem{
+				{Title: "First <post>", Link: "https://example.com/1", Description: "<p>Hello</p>", Content: "<p>Hello & welcome</p>", PubDate: "Mon, 02 Jan 2006 15:04:05 -0700", GUID: "post-1"},
+				{Title: "Second", Link: "https://example.com/2", PubDate: "2006-01-03T10:00:00Z"},
+			},
+		},
+		{
+			name: "rdf",
+			data: `<?xml version="1.0"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+<channel rdf:about="https://example.com/">
+	<title>Example RDF</title>
+	<link>https://example.com/</link>
+	<description>Old school</description>
+	<dc:language>en</dc:language>
+	<dc:date>2006-01-02T15:04:05Z</dc:date>
+</channel>
+<image rdf:about="https://example.com/logo.png">
+	<url>https://example.com/logo.png</url>
+</image>
+<item rdf:about="https://example.com/1">
+	<title>First</title>
+	<link>https://example.com/1</link>
+	<description>Hello</description>
+	<dc:date>2006-01-02T15:04:05Z</dc:date>
+</item>
+</rdf:RDF>`,
+			format:      FormatRDF,
+			title:       "Example RDF",
+			link:        "https://example.com/",
+			description: "Old school",
+			items: []RSSItem{
+				{Title: "First", Link: "https://example.com/1", Description: "Hello", PubDate: "2006-01-02T15:04:05Z"},
+			},
+		},
+		{
+			name: "atom",
+			data: `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xml:lang="en" xml:base="https://example.com/">
+	<title>Example Atom</title>
+	<subtitle>Entries</subtitle>
+	<link rel="self" href="https://example.com/atom.xml"/>
+	<link href="https://example.com/"/>
+	<updated>2006-01-02T15:04:05Z</updated>
+	<entry>
+		<title>Summarized</title>
+		<link rel="edit" href="https://example.com/edit/1"/>
+		<link rel="alternate" href="/1"/>
+		<id>urn:post:1</id>
+		<summary>Short</summary>
+		<content type="html">&lt;p&gt;Long&lt;/p&gt;</content>
+		<published>2006-01-02T15:04:05Z</published>
+		<updated>2006-01-05T15:04:05Z</updated>
+	</entry>
+	<entry xml:base="/posts/">
+		<title>Content only</title>
+		<link href="2"/>
+		<id>urn:post:2</id>
+		<content>Body</content>
+		<updated>2006-01-03T15:04:05Z</updated>
+	</entry>
+</feed>`,
+			format:      FormatAtom,
+			title:       "Example Atom",
+			link:        "https://example.com/",
+			description: "Entries",
+			items: []RSSItem{
+				{Title: "Summarized", Link: "/1", Description: "Short", Content: "<p>Long</p>", PubDate: "2006-01-02T15:04:05Z", GUID: "urn:post:1"},
+				{Title: "Content only", Link: "2", Description: "Body", PubDate: "2006-01-03T15:04:05Z", GUID: "urn:post:2", Base: "/posts/"},
+			},
+		},
+		{
+			name: "latin-1",
+			data: "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?>\n<rss version=\"2.0\"><channel><title>Caf\xe9</title>" +
+				"<item><title>Cr\xe8me br\xfbl\xe9e</title></item></channel></rss>",
+			format: FormatRSS,
+			title:  "Café",
+			items:  []RSSItem{{Title: "Crème brûlée"}},
+		},
+		{
+			name: "windows-1252",
+			data: "<?xml version='1.0' encoding='windows-1252'?>\n<rss version=\"2.0\"><channel><title>\x93Quoted\x94 \x80</title>" +
+				"<item><title>It\x92s \x96 fine\x85</title></item></channel></rss>",
+			format: FormatRSS,
+			title:  "“Quoted” €",
+			items:  []RSSItem{{Title: "It’s – fine…"}},
+		},
+		{
+			name: "html entities",
+			data: `<rss version="2.0"><channel><title>Caf&eacute; &ndash; news</title>
+<item><title>&copy; 2006&nbsp;Example</title></item></channel></rss>`,
+			format: FormatRSS,
+			title:  "Café – news",
+			items:  []RSSItem{{Title: "© 2006 Example"}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			feed, format, err := parseFeed([]byte(c.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if format != c.format {
+				t.Errorf("format = %q, want %q", format, c.format)
+			}
+			if feed.Channel.Title != c.title || feed.Channel.Link != c.link || feed.Channel.Description != c.description {
+				t.Errorf("channel = %q, %q, %q, want %q, %q, %q", feed.Channel.Title, feed.Channel.Link, feed.Channel.Description, c.title, c.link, c.description)
+			}
+			if len(feed.Channel.Item) != len(c.items) {
+				t.Fatalf("got %d items, want %d", len(feed.Channel.Item), len(c.items))
+			}
+			for i, want := range c.items {
+				got := feed.Channel.Item[i]
+				if got.Title != want.Title || got.Link != want.Link || got.Description != want.Description || got.Content != want.Content ||
+					got.PubDate != want.PubDate || got.GUID != want.GUID || got.Base != want.Base {
+					t.Errorf("item %d = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseFeedChannel(t *testing.T) {
+	rdf, _, err := parseFeed([]byte(`<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+<channel><title>RDF</title><dc:language>en</dc:language><dc:date>2006-01-02T15:04:05Z</dc:date></channel>
+<image><url>https://example.com/logo.png</url></image>
+</rdf:RDF>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rdf.Channel.Language != "en" || rdf.Channel.LastBuildDate != "2006-01-02T15:04:05Z" || rdf.Channel.Image.URL != "https://example.com/logo.png" {
+		t.Errorf("RDF channel = %+v", rdf.Channel)
+	}
+
+	atom, _, err := parseFeed([]byte(`<feed xmlns="http://www.w3.org/2005/Atom" xml:lang="pl" xml:base="https://example.com/blog/">
+<title>Atom</title><generator>Hugo</generator><updated>2006-01-02T15:04:05Z</updated>
+<icon>/favicon.ico</icon><logo>/logo.png</logo>
+</feed>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if atom.Channel.Language != "pl" || atom.Channel.Generator != "Hugo" || atom.Channel.LastBuildDate != "2006-01-02T15:04:05Z" ||
+		atom.Channel.Image.URL != "/logo.png" || atom.Channel.Base != "https://example.com/blog/" {
+		t.Errorf("Atom channel = %+v", atom.Channel)
+	}
+
+	icon, _, err := parseFeed([]byte(`<feed xmlns="http://www.w3.org/2005/Atom"><title>Atom</title><icon>/favicon.ico</icon></feed>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if icon.Channel.Image.URL != "/favicon.ico" {
+		t.Errorf("Atom image without logo = %q, want the icon", icon.Channel.Image.URL)
+	}
+}
+
+func TestParseFeedErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		data   string
+		format string
+	}{
+		{"empty", "", ""},
+		{"truncated html", "<html><body>Not found", ""},
+		{"plain text", "Not found", ""},
+		{"html document", "<html><body>Not found</body></html>", ""},
+		{"unsupported encoding", `<?xml version="1.0" encoding="Shift_JIS"?><rss version="2.0"></rss>`, ""},
+		{"broken rss", `<rss version="2.0"><channel><title>Broken</title><item>`, FormatRSS},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			feed, format, err := parseFeed([]byte(c.data))
+			if err == nil {
+				t.Fatalf("parseFeed(%q) = %+v, want error", c.data, feed)
+			}
+			if format != c.format {
+				t.Errorf("format = %q, want %q", format, c.format)
+			}
+		})
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	cases := []struct {
+		value string
+		want  time.Time
+		ok    bool
+	}{
+		{"Mon, 02 Jan 2006 15:04:05 -0700", time.Date(2006, 1, 2, 22, 4, 5, 0, time.UTC), true},
+		{"Mon, 02 Jan 2006 15:04:05 GMT", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC), true},
+		{"Mon, 2 Jan 2006 15:04:05 +0100", time.Date(2006, 1, 2, 14, 4, 5, 0, time.UTC), true},
+		{"Mon, 2 Jan 2006 15:04 -0700", time.Date(2006, 1, 2, 22, 4, 0, 0, time.UTC), true},
+		{"2 Jan 2006 15:04:05 +0000", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC), true},
+		{"02 Jan 06 15:04 +0000", time.Date(2006, 1, 2, 15, 4, 0, 0, time.UTC), true},
+		{"2006-01-02T15:04:05Z", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC), true},
+		{"2006-01-02T15:04:05.123+02:00", time.Date(2006, 1, 2, 13, 4, 5, 123000000, time.UTC), true},
+		{"2006-01-02T15:04:05", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC), true},
+		{"2006-01-02 15:04:05", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC), true},
+		{"2006-01-02", time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC), true},
+		{"  2006-01-02\n", time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC), true},
+		{"", time.Time{}, false},
+		{"yesterday", time.Time{}, false},
+		{"02/01/2006", time.Time{}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.value, func(t *testing.T) {
+			got, ok := parseDate(c.value)
+			if ok != c.ok || !got.Equal(c.want) {
+				t.Errorf("parseDate(%q) = %v, %v, want %v, %v", c.value, got, ok, c.want, c.ok)
+			}
+		})
+	}
+}
+
+func TestItemAuthors(t *testing.T) {
+	cases := []struct {
+		name string
+		item RSSItem
+		want []string
+	}{
+		{"none", RSSItem{}, nil},
+		{"email with name", RSSItem{Author: "jane@example.com (Jane Doe)"}, []string{"Jane Doe"}},
+		{"email only", RSSItem{Author: "jane@example.com"}, []string{"jane@example.com"}},
+		{"name only", RSSItem{Author: "  Jane Doe "}, []string{"Jane Doe"}},
+		{"creators", RSSItem{Creators: []string{"Jane Doe", "John Roe"}}, []string{"Jane Doe", "John Roe"}},
+		{"author and creators", RSSItem{Author: "jane@example.com (Jane Doe)", Creators: []string{"John Roe"}}, []string{"Jane Doe", "John Roe"}},
+		{"repeated", RSSItem{Author: "jane@example.com (Jane Doe)", Creators: []string{"Jane Doe", " Jane Doe "}}, []string{"Jane Doe"}},
+		{"empty and escaped", RSSItem{Author: " ", Creators: []string{"", "Jane &amp; John"}}, []string{"Jane & John"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := itemAuthors(c.item); !slices.Equal(got, c.want) {
+				t.Errorf("itemAuthors(%+v) = %q, want %q", c.item, got, c.want)
+			}
+		})
+	}
+}
+
+func TestParseFeedAuthorsAndCategories(t *testing.T) {
+	cases := []struct {
+		name       string
+		data       string
+		authors    [][]string
+		categories [][]string
+	}{
+		{
+			name: "rss",
+			data: `<rss version="2.0" xmlns:dc="http://purl.org/dc/elements/1.1/"><channel><title>RSS</title>
+<item>
+	<author>jane@example.com (Jane Doe)</author>
+	<dc:creator>John Roe</dc:creator>
+	<category>Go</category>
+	<category> Release Notes </category>
+	<dc:subject>Go</dc:subject>
+	<dc:subject>Tools</dc:subject>
+</item>
+<item><title>Nobody's</title></item>
+</channel></rss>`,
+			authors:    [][]string{{"Jane Doe", "John Roe"}, nil},
+			categories: [][]string{{"Go", "Release Notes", "Tools"}, nil},
+		},
+		{
+			name: "rdf",
+			data: `<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+<channel><title>RDF</title></channel>
+<item><dc:creator>Jane Doe</dc:creator><dc:subject>Science</dc:subject></item>
+</rdf:RDF>`,
+			authors:    [][]string{{"Jane Doe"}},
+			categories: [][]string{{"Science"}},
+		},
+		{
+			name: "atom",
+			data: `<feed xmlns="http://www.w3.org/2005/Atom"><title>Atom</title>
+<author><name>Feed Author</name></author>
+<entry>
+	<author><name>Jane Doe</name></author>
+	<author><name>John Roe</name></author>
+	<category term="go" label="Go"/>
+	<category label="Only label"/>
+</entry>
+<entry><title>Inherits</title></entry>
+</feed>`,
+			authors:    [][]string{{"Jane Doe", "John Roe"}, {"Feed Author"}},
+			categories: [][]string{{"go", "Only label"}, nil},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			feed, _, err := parseFeed([]byte(c.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(feed.Channel.Item) != len(c.authors) {
+				t.Fatalf("got %d items, want %d", len(feed.Channel.Item), len(c.authors))
+			}
+			for i, it := range feed.Channel.Item {
+				if !slices.Equal(it.Authors, c.authors[i]) {
+					t.Errorf("authors of item %d = %q, want %q", i, it.Authors, c.authors[i])
+				}
+				if !slices.Equal(it.Categories, c.categories[i]) {
+					t.Errorf("categories of item %d = %q, want %q", i, it.Categories, c.categories[i])
+				}
+			}
+		})
+	}
+}
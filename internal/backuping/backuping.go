@@ -0,0 +1,185 @@
+package backuping
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+	"github.com/mattn/go-sqlite3"
+)
+
+const DefaultKeep = 7
+
+// scheduled names backups made by 'agg', only these are rotated
+const scheduled = "scheduled"
+
+// Config is kept in the config file, 'agg' backs the database up every Interval when it is set,
+// keeping Keep newest scheduled backups, backups are kept in Dir, by default next to the database
+type Config struct {
+	Interval string `json:"interval,omitempty"`
+	Keep int `json:"keep,omitempty"`
+	Dir string `json:"dir,omitempty"`
+}
+
+// Dir returns directory of automatic backups for the database at given path
+func Dir(dbPath string, conf Config) string {
+	if conf.Dir != "" {
+		return conf.Dir
+	}
+	return filepath.Join(filepath.Dir(dbPath), "backups")
+}
+
+// Backup writes a consistent snapshot of the database into a new file, with VACUUM INTO it is safe while other processes write
+func Backup(ctx context.Context, db *sql.DB, path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("file \"%s\" already exists\n", path)
+	}
+
+	if _, err := db.ExecContext(ctx, "VACUUM INTO ?", path); err != nil {
+		return fmt.Errorf("error while backing up the database - %w\n", err)
+	}
+	return nil
+}
+
+// Snapshot backs the database up into given directory, naming the file after the time and the operation it was made for
+func Snapshot(ctx context.Context, db *sql.DB, dir string, operation string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("error while creating backups path - %w\n", err)
+	}
+
+	// backups made within the same second are numbered
+	name := fmt.Sprintf("gator-%s-%s", time.Now().Format("20060102-150405"), operation)
+	path := filepath.Join(dir, name+".db")
+	for i := 2; ; i++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			break
+		}
+		path = filepath.Join(dir, fmt.Sprintf("%s-%d.db", name, i))
+	}
+
+	return path, Backup(ctx, db, path)
+}
+
+// Version returns schema version of the database backed up at given path, failing when it isn't a sound gator database
+func Version(ctx context.Context, path string) (int64, error) {
+	if _, err := os.Stat(path); err != nil {
+		return 0, fmt.Errorf("error while opening backup - %w\n", err)
+	}
+	src, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return 0, fmt.Errorf("error while opening backup - %w\n", err)
+	}
+	defer src.Close()
+
+	var check string
+	if err := src.QueryRowContext(ctx, "PRAGMA quick_check").Scan(&check); err != nil || check != "ok" {
+		return 0, fmt.Errorf("\"%s\" is not a valid SQLite database\n", path)
+	}
+
+	var version int64
+	err = src.QueryRowContext(ctx, "SELECT COALESCE(MAX(version_id), 0) FROM goose_db_version").Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("\"%s\" is not a gator database\n", path)
+	}
+	return version, nil
+}
+
+// Restore replaces content of the database with the backup at given path using the online backup API,
+// so processes which have the database open see the restored data instead of a file swapped under them
+func Restore(ctx context.Context, db *sql.DB, path string) error {
+	src, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("error while opening backup - %w\n", err)
+	}
+	defer src.Close()
+
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("error while opening backup - %w\n", err)
+	}
+	defer srcConn.Close()
+	destConn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("error while opening the database - %w\n", err)
+	}
+	defer destConn.Close()
+
+	err = destConn.Raw(func(dest any) error {
+		return srcConn.Raw(func(src any) error {
+			backup, err := dest.(*sqlite3.SQLiteConn).Backup("main", src.(*sqlite3.SQLiteConn), "main")
+			if err != nil {
+				return err
+			}
+			if _, err := backup.Step(-1); err != nil {
+				backup.Finish()
+				return err
+			}
+			return backup.Finish()
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("error while restoring the database - %w\n", err)
+	}
+	return nil
+}
+
+// Due reports whether the newest scheduled backup in given directory is older than interval
+func Due(dir string, interval time.Duration) (bool, error) {
+	backups, err := scheduledBackups(dir)
+	if err != nil || len(backups) == 0 {
+		return true, err
+	}
+
+	info, err := os.Stat(backups[len(backups)-1])
+	if err != nil {
+		return false, fmt.Errorf("error while checking backups - %w\n", err)
+	}
+	return time.Since(info.ModTime()) >= interval, nil
+}
+
+// Scheduled makes a scheduled backup and removes the oldest ones, so only keep newest scheduled backups are left
+func Scheduled(ctx context.Context, db *sql.DB, dir string, keep int) (string, []string, error) {
+	path, err := Snapshot(ctx, db, dir, scheduled)
+	if err != nil {
+		return "", nil, err
+	}
+
+	backups, err := scheduledBackups(dir)
+	if err != nil {
+		return path, nil, err
+	}
+	var removed []string
+	for len(backups) > keep {
+		if err := os.Remove(backups[0]); err != nil {
+			return path, removed, fmt.Errorf("error while removing old backup - %w\n", err)
+		}
+		removed = append(removed, backups[0])
+		backups = backups[1:]
+	}
+	return path, removed, nil
+}
+
+// scheduledBackups lists scheduled backups from the oldest, by the time they were written at,
+// as names of backups made within the same second don't sort in the order they were made
+func scheduledBackups(dir string) ([]string, error) {
+	backups, err := filepath.Glob(filepath.Join(dir, "gator-*-"+scheduled+"*.db"))
+	if err != nil {
+		return nil, fmt.Errorf("error while listing backups - %w\n", err)
+	}
+
+	modTimes := make(map[string]time.Time, len(backups))
+	for _, backup := range backups {
+		info, err := os.Stat(backup)
+		if err != nil {
+			return nil, fmt.Errorf("error while listing backups - %w\n", err)
+		}
+		modTimes[backup] = info.ModTime()
+	}
+	sort.SliceStable(backups, func(i, j int) bool {
+		return modTimes[backups[i]].Before(modTimes[backups[j]])
+	})
+	return backups, nil
+}
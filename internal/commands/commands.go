@@ -10,6 +10,7 @@ func GetCommands() map[string]commands {
 			name: "help",
 			callback: cmdHelp,
 			description: "Displays this help message",
+			readOnly: true,
 		}, "register": {
 			name: "register <user_name>",
 			callback: cmdRegister,
@@ -18,18 +19,37 @@ func GetCommands() map[string]commands {
 			name: "login <user_name>",
 			callback: cmdLogin,
 			description: "Allows registered user to login onto existant account",
+			readOnly: true,
 		}, "users": {
 			name: "users",
 			callback: cmdUsers,
 			description: "Displays all registered users",
+			readOnly: true,
+		}, "renameuser": {
+			name: "renameuser <user_name> <new_user_name>",
+			callback: cmdRenameUser,
+			description: "Allows to rename any user, logged in user stays logged in",
+		}, "deluser": {
+			name: "deluser [--yes] <user_name>",
+			callback: cmdDeleteUser,
+			description: "Removes given user together with their follows, read and starred posts and filters, after confirmation and a backup of the database, feeds they added are handed over to other followers",
 		}, "addfeed": {
-			name: "addfeed <feed_name> <feed_url>",
+			name: "addfeed [--no-validate] <(optional) feed_name> <feed_url>",
 			callback: middlewareLoggedIn(cmdAddFeed),
-			description: "Allows to save and follow a new RSS feed",
+			description: "Allows to save and follow a new RSS feed, the feed is validated and its posts are fetched at once, the name defaults to the feed's title",
 		}, "feeds": {
 			name: "feeds",
 			callback: cmdFeeds,
 			description: "Displays all feeds saved by users",
+			readOnly: true,
+		}, "editfeed": {
+			name: "editfeed [--name <name>] [--url <new_url>] [--no-validate] <feed_url>",
+			callback: middlewareLoggedIn(cmdEditFeed),
+			description: "Allows to rename a feed that you added (or a followed feed without owner) or to change its URL, the new URL is validated unless '--no-validate' is given",
+		}, "rmfeed": {
+			name: "rmfeed [--yes] [--keep-posts] [--delete-starred] <feed_url>",
+			callback: middlewareLoggedIn(cmdRemoveFeed),
+			description: "Allows to remove a feed that you added (or a followed feed without owner) together with its follows and posts, asking first when other users follow it, starred posts are kept with the feed archived (still followed by users who starred them) unless '--delete-starred' is given, '--keep-posts' archives the feed instead so it is no longer fetched",
 		}, "follow": {
 			name: "follow <feed_url>",
 			callback: middlewareLoggedIn(cmdFollow),
@@ -39,21 +59,112 @@ func GetCommands() map[string]commands {
 			callback: middlewareLoggedIn(cmdUnfollow),
 			description: "Allows to unfollow any followed feed",
 		}, "following": {
-			name: "following",
+			name: "following [--tag <tag>]",
 			callback: middlewareLoggedIn(cmdFollowing),
-			description: "Displays every feed that you follow",
+			description: "Displays every feed that you follow with its tags and number of unread posts, may be limited to feeds with given tag",
+			readOnly: true,
+		}, "rename": {
+			name: "rename [--reset] <feed_url> <name>",
+			callback: middlewareLoggedIn(cmdRename),
+			description: "Sets your own name of a followed feed, used when displaying and filtering posts, --reset brings back the feed's name",
+		}, "tag": {
+			name: "tag <feed_url> <tag...>",
+			callback: middlewareLoggedIn(cmdTag),
+			description: "Assigns given tags (folders) to a feed that you follow",
+		}, "untag": {
+			name: "untag <feed_url> <tag...>",
+			callback: middlewareLoggedIn(cmdUntag),
+			description: "Removes given tags from a feed that you follow",
+		}, "tags": {
+			name: "tags",
+			callback: middlewareLoggedIn(cmdTags),
+			description: "Displays your tags together with the number of tagged feeds",
+			readOnly: true,
 		}, "agg": {
 			name: "agg <time_between_reqs [1s, 1m, 2h, 3m45s, ...(default = 1m)]>",
 			callback: cmdAgg,
 			description: "Starts the automatic feeds aggregation and fetches new posts whenever given time passes",
+		}, "fetch": {
+			name: "fetch [--all] [--interval <duration [default = 30m]>] <(optional) feed_url...>",
+			callback: cmdFetch,
+			description: "Fetches given feeds, all followed feeds or ones not fetched within given interval once and exits, failing when any feed fails",
+		}, "check": {
+			name: "check [--preview <items [default = 3]>] <feed_url>",
+			callback: cmdCheck,
+			description: "Fetches and parses given feed without saving anything, reporting its format, encoding, caching headers, redirects and items",
+			readOnly: true,
 		}, "browse": {
-			name: "browse <limit [default = 2]> <(optional) search_query>",
+			name: "browse [--all] [--limit <limit [default = 2]>] [--sort published|fetched] [--asc] [--offset <offset>] [--cursor <cursor>] [--tag <tag>] <(optional) query>",
 			callback: middlewareLoggedIn(cmdBrowse),
-			description: "Displays number of freshly fetched posts for current user, limited by given value, may be filtered by specified feed name's part",
+			description: "Displays freshly fetched unread posts for current user, read posts are included with --all, the query may hold words matched against title and description and feed:, tag:, title:, author:, category:, after:, before:, is:unread, is:read, is:starred terms",
+			readOnly: true,
+		}, "search": {
+			name: "search [--feed <feed_url>] [--tag <tag>] [--after <date>] [--before <date>] [--limit <limit [default = 10]>] <query>",
+			callback: middlewareLoggedIn(cmdSearch),
+			description: "Searches titles, descriptions and content of posts from feeds that you follow, supporting \"phrases\", prefix* and AND, OR, NOT operators, best matches first",
+			readOnly: true,
+		}, "read": {
+			name: "read [--feed <feed_url>] [--tag <tag>] [--before <date [YYYY-MM-DD, ...]>] <(optional) post_id...>",
+			callback: middlewareLoggedIn(cmdRead),
+			description: "Marks given posts, every post of given feed or feeds with given tag or every post published before given date as read",
+		}, "unread": {
+			name: "unread [--feed <feed_url>] [--tag <tag>] [--before <date [YYYY-MM-DD, ...]>] <(optional) post_id...>",
+			callback: middlewareLoggedIn(cmdUnread),
+			description: "Marks given posts, every post of given feed or feeds with given tag or every post published before given date as unread",
+		}, "star": {
+			name: "star <post_id...>",
+			callback: middlewareLoggedIn(cmdStar),
+			description: "Stars given posts, starred posts are never pruned",
+		}, "unstar": {
+			name: "unstar <post_id...>",
+			callback: middlewareLoggedIn(cmdUnstar),
+			description: "Removes star from given posts",
+		}, "starred": {
+			name: "starred [--export json|csv]",
+			callback: middlewareLoggedIn(cmdStarred),
+			description: "Displays your starred posts or exports them in given format",
+			readOnly: true,
+		}, "addfilter": {
+			name: "addfilter [--global] [--drop] [--match substring|regex|glob] <field [feed, title, description, author, category, url]> <pattern>",
+			callback: middlewareLoggedIn(cmdAddFilter),
+			description: "Allows to hide posts matching given pattern while browsing, global filters apply to every user and may drop posts before they are saved",
+		}, "filters": {
+			name: "filters",
+			callback: middlewareLoggedIn(cmdFilters),
+			description: "Displays your and global filters",
+			readOnly: true,
+		}, "rmfilter": {
+			name: "rmfilter [--global] [--yes] <filter_id>",
+			callback: middlewareLoggedIn(cmdRemoveFilter),
+			description: "Allows to remove any of your filters, global ones are removed only with '--global' after confirmation, unless '--yes' is given",
+		}, "mergefeeds": {
+			name: "mergefeeds [--dry-run]",
+			callback: cmdMergeFeeds,
+			description: "Finds feeds saved under different forms of the same URL and merges them, moving follows and posts",
+		}, "prune": {
+			name: "prune --before <date [YYYY-MM-DD, ...]>",
+			callback: cmdPrune,
+			description: "Removes posts published before given date, except for starred ones",
+		}, "gc": {
+			name: "gc [--grace <duration [default = 720h]>] [--dry-run]",
+			callback: cmdGC,
+			description: "Removes feeds nobody follows together with their posts, once they haven't been fetched for the grace period, feeds with starred posts are archived keeping only the starred ones",
+		}, "migrate": {
+			name: "migrate [--yes] status | up | down | to <version> | redo",
+			callback: cmdMigrate,
+			description: "Shows applied and pending migrations of the database schema or migrates it up, down by one, to given version or redoes the latest migration, asking first before moving it down, other commands migrate only a fresh database on their own and refuse to run on an older schema",
+		}, "backup": {
+			name: "backup <path>",
+			callback: cmdBackup,
+			description: "Saves a consistent snapshot of the database into given file or directory, safe to run while 'agg' is working",
+		}, "restore": {
+			name: "restore [--yes] <path>",
+			callback: cmdRestore,
+			description: "Replaces all saved data with given backup after confirmation, the current database is backed up first",
 		}, "reset": {
-			name: "reset",
+			name: "reset [--yes] --posts | --user <user_name> | --all",
 			callback: cmdReset,
-			description: "Resets all saved data",
+			description: "Removes every post, personal data (follows, tags, read and starred posts, filters) of given user or all saved data including global filters, after confirmation and a backup of the database",
 		},
 	}
 }
@@ -62,6 +173,8 @@ type commands struct {
 	name string
 	callback func(*config.State, Command) error
 	description string
+	// readOnly commands only display data, so they get read-only connections to the database
+	readOnly bool
 }
 
 type Command struct {
@@ -69,6 +182,10 @@ type Command struct {
 	Args []string
 }
 
+func (c commands) ReadOnly() bool {
+	return c.readOnly
+}
+
 func (c commands) Run(s *config.State, cmd Command) error {
 	err := c.callback(s, cmd)
 	if err != nil {
@@ -0,0 +1,305 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+	"github.com/MedrekIT/gator/internal/aggregating"
+	"github.com/MedrekIT/gator/internal/config"
+	"github.com/MedrekIT/gator/internal/database"
+	"github.com/MedrekIT/gator/internal/rewriting"
+)
+
+// cmdEditFeed renames feed or moves it to a new URL, only the user who added the feed may edit it
+func cmdEditFeed(s *config.State, cmd Command, user database.User) error {
+	usage := "editfeed [--name <name>] [--url <new_url>] [--no-validate] <feed_url>"
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	name := fs.String("name", "", "")
+	newURL := fs.String("url", "", "")
+	noValidate := fs.Bool("no-validate", false, "")
+	args, err := parseFlags(fs, cmd.Args, usage)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 || (*name == "" && *newURL == "") {
+		return fmt.Errorf("Incorrect usage\nTry '%s'\n", usage)
+	}
+
+	ctx := context.Background()
+	feed, err := getOwnedFeed(ctx, s, user, args[0])
+	if err != nil {
+		return err
+	}
+
+	feedURL := ""
+	if *newURL != "" {
+		feedURL, err = rewriting.NormalizeFeedURL(*newURL)
+		if err != nil {
+			return err
+		}
+
+		// the same feed may be found under the new URL when only its form changes, e.g. the scheme
+		conflict, err := getFeedByURL(ctx, s, feedURL)
+		if err == nil && conflict.ID != feed.ID {
+			return fmt.Errorf("feed \"%s\" is already saved under given URL, use 'mergefeeds' if both are the same feed\n", conflict.Name)
+		}
+		if err != nil && !errors.Is(err, database.ErrNotFound) {
+			return fmt.Errorf("error while getting feed from the database - %w\n", err)
+		}
+
+		if !*noValidate {
+			fetchCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			_, _, err = aggregating.FetchFeed(fetchCtx, feedURL)
+			cancel()
+			if err != nil {
+				return fmt.Errorf("given URL is not a valid feed - %vUse '--no-validate' to change it anyway\n", err)
+			}
+		}
+	}
+
+	feedName := strings.TrimSpace(*name)
+	if *name != "" && feedName == "" {
+		return fmt.Errorf("feed name can't be empty\n")
+	}
+
+	// both changes are saved together, so a failed rename doesn't leave the feed moved
+	err = s.Db.InTx(ctx, func(db *database.Store) error {
+		if feedURL != "" {
+			newUpdateFeedParams := database.UpdateFeedURLParams{
+				ID: feed.ID,
+				Url: feedURL,
+				UpdatedAt: time.Now(),
+			}
+			_, err := db.UpdateFeedURL(ctx, newUpdateFeedParams)
+			if err != nil {
+				if errors.Is(err, database.ErrDuplicateFeed) {
+					return fmt.Errorf("another feed is already saved under given URL\n")
+				}
+				return fmt.Errorf("error while updating feed URL in the database - %w\n", err)
+			}
+		}
+
+		if feedName != "" {
+			newUpdateFeedParams := database.UpdateFeedNameParams{
+				ID: feed.ID,
+				Name: feedName,
+				UpdatedAt: time.Now(),
+			}
+			_, err := db.UpdateFeedName(ctx, newUpdateFeedParams)
+			if err != nil {
+				return fmt.Errorf("error while updating feed name in the database - %w\n", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if feedURL != "" {
+		fmt.Printf("Feed \"%s\" has been moved to %s!\n", feed.Name, feedURL)
+	}
+	if feedName != "" {
+		fmt.Printf("Feed \"%s\" has been renamed to \"%s\"!\n", feed.Name, feedName)
+	}
+	return nil
+}
+
+// cmdRemoveFeed removes feed together with its follows and posts, posts which came from other feeds as well are kept there,
+// starred posts are kept as well, with the feed archived to hold them, unless --delete-starred is given,
+// with --keep-posts the feed is archived instead, it stops being fetched but its posts stay readable for its followers
+func cmdRemoveFeed(s *config.State, cmd Command, user database.User) error {
+	usage := "rmfeed [--yes] [--keep-posts] [--delete-starred] <feed_url>"
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	yes := fs.Bool("yes", false, "")
+	keepPosts := fs.Bool("keep-posts", false, "")
+	deleteStarred := fs.Bool("delete-starred", false, "")
+	args, err := parseFlags(fs, cmd.Args, usage)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 || (*keepPosts && *deleteStarred) {
+		return fmt.Errorf("Incorrect usage\nTry '%s'\n", usage)
+	}
+
+	ctx := context.Background()
+	feed, err := getOwnedFeed(ctx, s, user, args[0])
+	if err != nil {
+		return err
+	}
+	if *keepPosts && feed.ArchivedAt.Valid {
+		return fmt.Errorf("feed \"%s\" is already archived\n", feed.Name)
+	}
+
+	followers, err := s.Db.CountOtherFollowers(ctx, database.CountOtherFollowersParams{
+		FeedID: feed.ID,
+		UserID: user.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("error while counting feed followers - %w\n", err)
+	}
+	var starred int64
+	if !*keepPosts {
+		starred, err = s.Db.CountStarredPostsOfFeed(ctx, feed.ID)
+		if err != nil {
+			return fmt.Errorf("error while counting starred posts - %w\n", err)
+		}
+		if starred != 0 && !*deleteStarred {
+			fmt.Printf("%d posts of feed \"%s\" are starred, they will be kept with the feed archived, use --delete-starred to remove them as well\n", starred, feed.Name)
+		}
+	}
+
+	if (followers != 0 || (starred != 0 && *deleteStarred)) && !*yes {
+		question := fmt.Sprintf("Feed \"%s\" is followed by %d other users, remove it anyway?", feed.Name, followers)
+		if starred != 0 && *deleteStarred {
+			question = fmt.Sprintf("%d posts of feed \"%s\" are starred, remove it together with them?", starred, feed.Name)
+			if followers != 0 {
+				question = fmt.Sprintf("Feed \"%s\" is followed by %d other users and %d of its posts are starred, remove it together with starred posts?", feed.Name, followers, starred)
+			}
+		}
+
+		ok, err := confirm(question)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Printf("Feed \"%s\" has been kept!\n", feed.Name)
+			return nil
+		}
+	}
+
+	if *keepPosts {
+		err = s.Db.ArchiveFeed(ctx, database.ArchiveFeedParams{
+			ID: feed.ID,
+			ArchivedAt: sql.NullTime{
+				Time: time.Now(),
+				Valid: true,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("error while archiving feed - %w\n", err)
+		}
+
+		fmt.Printf("Feed \"%s\" has been archived, its posts are kept but it won't be fetched anymore!\n", feed.Name)
+		return nil
+	}
+
+	if err := backupDb(ctx, s, "rmfeed"); err != nil {
+		return err
+	}
+	return removeFeed(ctx, s, feed, !*deleteStarred)
+}
+
+// removeFeed deletes feed step by step in a single transaction, so every removed follow and post is accounted for,
+// posts also delivered by other feeds are handed over to one of them instead of being deleted,
+// with keepStarred posts starred by any user are kept and the feed is archived to hold them instead of being deleted,
+// users who starred them keep following the archived feed
+func removeFeed(ctx context.Context, s *config.State, feed database.Feed, keepStarred bool) error {
+	var follows, kept, removed, starred int64
+	err := s.Db.InTx(ctx, func(db *database.Store) error {
+		var err error
+		kept, err = db.ReassignPostsOfFeed(ctx, feed.ID)
+		if err != nil {
+			return fmt.Errorf("error while moving shared posts to other feeds - %w\n", err)
+		}
+		if keepStarred {
+			starred, err = db.CountStarredPostsOfFeed(ctx, feed.ID)
+			if err != nil {
+				return fmt.Errorf("error while counting starred posts - %w\n", err)
+			}
+		}
+
+		if starred != 0 {
+			// users who starred posts of the feed keep following it, so the posts are still shown to them
+			follows, err = db.DeleteUnstarredFeedFollowsForFeed(ctx, feed.ID)
+			if err != nil {
+				return fmt.Errorf("error while removing feed follows - %w\n", err)
+			}
+			removed, err = db.DeleteUnstarredPostsOfFeed(ctx, feed.ID)
+			if err != nil {
+				return fmt.Errorf("error while removing feed posts - %w\n", err)
+			}
+			// starred posts keep their source, so they are still shown as coming from the archived feed
+			err = db.DeleteSharedPostSourcesForFeed(ctx, feed.ID)
+			if err != nil {
+				return fmt.Errorf("error while removing post sources - %w\n", err)
+			}
+			if feed.ArchivedAt.Valid {
+				return nil
+			}
+			err = db.ArchiveFeed(ctx, database.ArchiveFeedParams{
+				ID: feed.ID,
+				ArchivedAt: sql.NullTime{
+					Time: time.Now(),
+					Valid: true,
+				},
+			})
+			if err != nil {
+				return fmt.Errorf("error while archiving feed - %w\n", err)
+			}
+			return nil
+		}
+
+		follows, err = db.DeleteFeedFollowsForFeed(ctx, feed.ID)
+		if err != nil {
+			return fmt.Errorf("error while removing feed follows - %w\n", err)
+		}
+		err = db.DeletePostSourcesForFeed(ctx, feed.ID)
+		if err != nil {
+			return fmt.Errorf("error while removing post sources - %w\n", err)
+		}
+		removed, err = db.DeletePostsOfFeed(ctx, feed.ID)
+		if err != nil {
+			return fmt.Errorf("error while removing feed posts - %w\n", err)
+		}
+
+		err = db.DeleteFeed(ctx, feed.ID)
+		if err != nil {
+			return fmt.Errorf("error while removing feed from the database - %w\n", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if starred != 0 {
+		fmt.Printf("Feed \"%s\" has been archived to keep %d starred posts, %d follows and %d other posts have been removed, %d posts shared with other feeds were kept!\n", feed.Name, starred, follows, removed, kept)
+		return nil
+	}
+	fmt.Printf("Feed \"%s\" has been removed together with %d follows and %d posts, %d posts shared with other feeds were kept!\n", feed.Name, follows, removed, kept)
+	return nil
+}
+
+// getOwnedFeed finds feed with given URL, failing unless it has been added by given user,
+// feeds left without owner after their creator was removed may be changed by any of their followers
+func getOwnedFeed(ctx context.Context, s *config.State, user database.User, feedURL string) (database.Feed, error) {
+	feed, err := getFeedByURL(ctx, s, feedURL)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return database.Feed{}, fmt.Errorf("given feed does not exist in the database\n")
+		}
+		return database.Feed{}, fmt.Errorf("error while getting feed from the database - %w\n", err)
+	}
+
+	if !feed.UserID.Valid {
+		_, err = s.Db.GetFeedFollow(ctx, database.GetFeedFollowParams{
+			UserID: user.ID,
+			FeedID: feed.ID,
+		})
+		if err != nil {
+			if errors.Is(err, database.ErrNotFound) {
+				return database.Feed{}, fmt.Errorf("feed \"%s\" has no owner, only its followers can change it\n", feed.Name)
+			}
+			return database.Feed{}, fmt.Errorf("error while getting follow from the database - %w\n", err)
+		}
+	} else if feed.UserID.String != user.ID {
+		return database.Feed{}, fmt.Errorf("feed \"%s\" has been added by another user, only they can change it\n", feed.Name)
+	}
+
+	return feed, nil
+}
@@ -0,0 +1,145 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"time"
+	"github.com/google/uuid"
+	"github.com/MedrekIT/gator/internal/config"
+	"github.com/MedrekIT/gator/internal/database"
+	"github.com/MedrekIT/gator/internal/filtering"
+)
+
+func cmdAddFilter(s *config.State, cmd Command, user database.User) error {
+	usage := "addfilter [--global] [--drop] [--match substring|regex|glob] <field> <pattern>"
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	global := fs.Bool("global", false, "")
+	drop := fs.Bool("drop", false, "")
+	matchType := fs.String("match", filtering.MatchSubstring, "")
+	args, err := parseFlags(fs, cmd.Args, usage)
+	if err != nil {
+		return err
+	}
+	if len(args) != 2 {
+		return fmt.Errorf("Incorrect usage\nTry '%s'\n", usage)
+	}
+
+	action := filtering.ActionHide
+	if *drop {
+		if !*global {
+			return fmt.Errorf("only global filters may drop posts, personal filters can only hide them\n")
+		}
+		action = filtering.ActionDrop
+	}
+
+	rule, err := filtering.NewRule(args[0], *matchType, args[1], action)
+	if err != nil {
+		return err
+	}
+
+	userID := sql.NullString{
+		String: user.ID,
+		Valid: !*global,
+	}
+	newFilterParams := database.CreateFilterParams{
+		ID: uuid.New().String(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID: userID,
+		Field: rule.Field,
+		MatchType: rule.MatchType,
+		Pattern: args[1],
+		Action: rule.Action,
+	}
+	filter, err := s.Db.CreateFilter(context.Background(), newFilterParams)
+	if err != nil {
+		return fmt.Errorf("error while creating new filter - %w\n", err)
+	}
+
+	fmt.Printf("Filter %s has been added!\n", shortID(filter.ID))
+	return nil
+}
+
+func cmdFilters(s *config.State, cmd Command, user database.User) error {
+	userID := sql.NullString{
+		String: user.ID,
+		Valid: true,
+	}
+	filters, err := s.Db.GetFiltersForUser(context.Background(), userID)
+	if err != nil {
+		return fmt.Errorf("error while getting filters from the database - %w\n", err)
+	}
+
+	if len(filters) == 0 {
+		fmt.Printf("You don't have any filters!\n")
+	}
+	for _, f := range filters {
+		scope := "personal"
+		if !f.UserID.Valid {
+			scope = "global"
+		}
+		fmt.Printf("* %s [%s, %s] %s %s \"%s\"\n", shortID(f.ID), scope, f.Action, f.Field, f.MatchType, f.Pattern)
+	}
+	return nil
+}
+
+// cmdRemoveFilter removes one of user's filters, global ones apply to every user, so they have to be removed with --global
+// and after confirmation
+func cmdRemoveFilter(s *config.State, cmd Command, user database.User) error {
+	usage := "rmfilter [--global] [--yes] <filter_id>"
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	global := fs.Bool("global", false, "")
+	yes := fs.Bool("yes", false, "")
+	args, err := parseFlags(fs, cmd.Args, usage)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("Incorrect usage\nTry '%s'\n", usage)
+	}
+
+	newGetFiltersParams := database.GetFiltersByIDPrefixParams{
+		IDPrefix: args[0],
+		UserID: sql.NullString{
+			String: user.ID,
+			Valid: true,
+		},
+	}
+	filters, err := s.Db.GetFiltersByIDPrefix(context.Background(), newGetFiltersParams)
+	if err != nil {
+		return fmt.Errorf("error while getting filters from the database - %w\n", err)
+	}
+	if len(filters) == 0 {
+		return fmt.Errorf("filter with given ID does not exist\n")
+	}
+	if len(filters) > 1 {
+		return fmt.Errorf("given ID matches more than one filter, try using a longer part of it\n")
+	}
+
+	filter := filters[0]
+	if !filter.UserID.Valid {
+		if !*global {
+			return fmt.Errorf("filter %s is global and applies to every user, use '--global' to remove it anyway\n", shortID(filter.ID))
+		}
+		if !*yes {
+			ok, err := confirm(fmt.Sprintf("Remove global filter %s for every user?", shortID(filter.ID)))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				fmt.Printf("Filter %s has been kept!\n", shortID(filter.ID))
+				return nil
+			}
+		}
+	}
+
+	err = s.Db.DeleteFilter(context.Background(), filter.ID)
+	if err != nil {
+		return fmt.Errorf("error while removing filter from the database - %w\n", err)
+	}
+
+	fmt.Printf("Filter %s has been removed!\n", shortID(filter.ID))
+	return nil
+}
@@ -2,7 +2,6 @@ package commands
 
 import (
 	"strings"
-	"strconv"
 	"context"
 	"os"
 	"os/signal"
@@ -11,10 +10,15 @@ import (
 	"log"
 	"fmt"
 	"database/sql"
+	"errors"
+	"flag"
 	"github.com/google/uuid"
 	"github.com/MedrekIT/gator/internal/config"
 	"github.com/MedrekIT/gator/internal/aggregating"
 	"github.com/MedrekIT/gator/internal/database"
+	"github.com/MedrekIT/gator/internal/filtering"
+	"github.com/MedrekIT/gator/internal/querying"
+	"github.com/MedrekIT/gator/internal/rewriting"
 )
 
 func cmdHelp(s *config.State, cmd Command) error {
@@ -32,7 +36,7 @@ func cmdLogin(s *config.State, cmd Command) error {
 
 	user, err := s.Db.GetUser(context.Background(), cmd.Args[0])
 	if err != nil {
-		if strings.Contains(err.Error(), "sql: no rows in result set") {
+		if errors.Is(err, database.ErrNotFound) {
 			return fmt.Errorf("user with given name does not exist in the database\n")
 		}
 		return fmt.Errorf("error while getting user from the database - %w\n", err)
@@ -60,7 +64,7 @@ func cmdRegister(s *config.State, cmd Command) error {
 	}
 	user, err := s.Db.CreateUser(context.Background(), newUserParams)
 	if err != nil {
-		if strings.Contains(err.Error(), "UNIQUE constraint failed: users.name") {
+		if errors.Is(err, database.ErrDuplicateUser) {
 			return fmt.Errorf("user with given name already exists in the database\n")
 		}
 		return fmt.Errorf("error while creating new user - %w\n", err)
@@ -95,47 +99,111 @@ func cmdUsers(s *config.State, cmd Command) error {
 }
 
 func cmdAddFeed(s *config.State, cmd Command, user database.User) error {
-	if len(cmd.Args) != 2 {
-		return fmt.Errorf("Incorrect usage\nTry 'addfeed <feed_name> <feed_url>'\n")
+	usage := "addfeed [--no-validate] <(optional) feed_name> <feed_url>"
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	noValidate := fs.Bool("no-validate", false, "")
+	args, err := parseFlags(fs, cmd.Args, usage)
+	if err != nil {
+		return err
 	}
-
-	newFeedParams := database.CreateFeedParams{
-		ID: uuid.New().String(),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-		Name: cmd.Args[0],
-		Url: cmd.Args[1],
-		UserID: user.ID,
+	if len(args) != 1 && len(args) != 2 {
+		return fmt.Errorf("Incorrect usage\nTry '%s'\n", usage)
 	}
-	feed, err := s.Db.CreateFeed(context.Background(), newFeedParams)
+	feedName := ""
+	if len(args) == 2 {
+		feedName = args[0]
+	}
+
+	feedURL, err := rewriting.NormalizeFeedURL(args[len(args)-1])
 	if err != nil {
-		if strings.Contains(err.Error(), "UNIQUE constraint failed: feeds.url") {
-			return fmt.Errorf("feed with given URL already exists in the database\n")
+		return err
+	}
+	_, err = getFeedByURL(context.Background(), s, feedURL)
+	if err == nil {
+		return fmt.Errorf("feed with given URL already exists in the database\n")
+	}
+	if !errors.Is(err, database.ErrNotFound) {
+		return fmt.Errorf("error while getting feed from the database - %w\n", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	var fetchedFeed *aggregating.RSSFeed
+	if !*noValidate {
+		fetchedFeed, _, err = aggregating.FetchFeed(ctx, feedURL)
+		if err != nil {
+			return fmt.Errorf("given URL is not a valid feed - %vUse '--no-validate' to add it anyway\n", err)
+		}
+		if feedName == "" {
+			feedName = fetchedFeed.Channel.Title
 		}
-		return fmt.Errorf("error while creating new feed - %w\n", err)
+	}
+	if feedName == "" {
+		return fmt.Errorf("feed name couldn't be taken from the feed, please specify it\nTry '%s'\n", usage)
 	}
 
-	newFeedFollowParams := database.CreateFeedFollowParams{
+	newFeedParams := database.CreateFeedParams{
 		ID: uuid.New().String(),
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
-		UserID: user.ID,
-		FeedID: feed.ID,
+		Name: feedName,
+		Url: feedURL,
+		UserID: sql.NullString{
+			String: user.ID,
+			Valid: true,
+		},
 	}
-	feedFollow, err := s.Db.CreateFeedFollow(context.Background(), newFeedFollowParams)
-	if err != nil {
-		if strings.Contains(err.Error(), "UNIQUE constraint failed: feed_follows.user_id, feed_follows.feed_id") {
-			return fmt.Errorf("you already follow feed with given URL\n")
+	// the feed is saved together with its first follow, so a failed follow doesn't leave the feed behind
+	var feed database.Feed
+	var feedFollow database.CreateFeedFollowRow
+	err = s.Db.InTx(context.Background(), func(db *database.Store) error {
+		feed, err = db.CreateFeed(context.Background(), newFeedParams)
+		if err != nil {
+			if errors.Is(err, database.ErrDuplicateFeed) {
+				return fmt.Errorf("feed with given URL already exists in the database\n")
+			}
+			return fmt.Errorf("error while creating new feed - %w\n", err)
+		}
+
+		newFeedFollowParams := database.CreateFeedFollowParams{
+			ID: uuid.New().String(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			UserID: user.ID,
+			FeedID: feed.ID,
+		}
+		feedFollow, err = db.CreateFeedFollow(context.Background(), newFeedFollowParams)
+		if err != nil {
+			if errors.Is(err, database.ErrAlreadyFollowing) {
+				return fmt.Errorf("you already follow feed with given URL\n")
+			}
+			return fmt.Errorf("error while adding follow to the database - %w\n", err)
 		}
-		return fmt.Errorf("error while adding follow to the database - %w\n", err)
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
 	fmt.Printf("User \"%s\" now follows feed \"%s\"!\n", feedFollow.UserName, feedFollow.FeedName)
+
+	// unvalidated feeds are fetched on a best-effort basis, their posts will be fetched later by 'agg' otherwise
+	var res aggregating.Result
+	if fetchedFeed != nil {
+		res = aggregating.SavePosts(ctx, s, feed, fetchedFeed)
+	} else {
+		res = aggregating.ScrapeFeed(ctx, s, feed)
+	}
+	if res.Err != nil {
+		fmt.Printf("Couldn't fetch posts yet - %v", res.Err)
+		return nil
+	}
+	fmt.Printf("%d posts are ready to browse!\n", res.NewPosts)
 	return nil
 }
 
 func cmdFeeds(s *config.State, cmd Command) error {
-	feeds, err := s.Db.GetFeeds(context.Background())
+	feeds, err := s.Db.GetFeedsWithOwners(context.Background())
 	if err != nil {
 		return fmt.Errorf("error while getting feeds from the database - %w\n", err)
 	}
@@ -143,18 +211,22 @@ func cmdFeeds(s *config.State, cmd Command) error {
 	if len(feeds) == 0 {
 		fmt.Printf("No feeds in the database!\n")
 	}
-	for _, feed := range feeds {
-		user, err := s.Db.GetUserByID(context.Background(), feed.UserID)
-		if err != nil {
-			if strings.Contains(err.Error(), "sql: no rows in result set") {
-				return fmt.Errorf("user with given ID does not exist in the database\n")
-			}
-			return fmt.Errorf("error while getting user from the database - %w\n", err)
+	for _, row := range feeds {
+		feed := row.Feed
+		// feeds whose creator has been removed belong to nobody
+		owner := "(no owner)"
+		if row.OwnerName.Valid {
+			owner = row.OwnerName.String
 		}
 
-		fmt.Printf("\"%s\":\n", feed.Name)
+		if feed.ArchivedAt.Valid {
+			fmt.Printf("\"%s\" (archived):\n", feed.Name)
+		} else {
+			fmt.Printf("\"%s\":\n", feed.Name)
+		}
 		fmt.Printf(" * %s\n", feed.Url)
-		fmt.Printf(" * %s\n", user.Name)
+		fmt.Printf(" * %s\n", owner)
+		printFeedMetadata(feed)
 	}
 	return nil
 }
@@ -164,13 +236,16 @@ func cmdFollow(s *config.State, cmd Command, user database.User) error {
 		return fmt.Errorf("Incorrect usage\nTry 'follow <feed_url>'\n")
 	}
 
-	feed, err := s.Db.GetFeedByURL(context.Background(), cmd.Args[0])
+	feed, err := getFeedByURL(context.Background(), s, cmd.Args[0])
 	if err != nil {
-		if strings.Contains(err.Error(), "sql: no rows in result set") {
+		if errors.Is(err, database.ErrNotFound) {
 			return fmt.Errorf("feed with given URL does not exist in the database\n")
 		}
 		return fmt.Errorf("error while getting feed from the database - %w\n", err)
 	}
+	if feed.ArchivedAt.Valid {
+		return fmt.Errorf("feed \"%s\" has been archived and can't be followed anymore\n", feed.Name)
+	}
 
 	newFeedFollowParams := database.CreateFeedFollowParams{
 		ID: uuid.New().String(),
@@ -181,7 +256,7 @@ func cmdFollow(s *config.State, cmd Command, user database.User) error {
 	}
 	feedFollow, err := s.Db.CreateFeedFollow(context.Background(), newFeedFollowParams)
 	if err != nil {
-		if strings.Contains(err.Error(), "UNIQUE constraint failed: feed_follows.user_id, feed_follows.feed_id") {
+		if errors.Is(err, database.ErrAlreadyFollowing) {
 			return fmt.Errorf("you already follow feed with given URL\n")
 		}
 		return fmt.Errorf("error while adding follow to the feed in the database - %w\n", err)
@@ -192,7 +267,26 @@ func cmdFollow(s *config.State, cmd Command, user database.User) error {
 }
 
 func cmdFollowing(s *config.State, cmd Command, user database.User) error {
-	userFollows, err := s.Db.GetFeedFollowsForUser(context.Background(), user.ID)
+	usage := "following [--tag <tag>]"
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	tagFlag := fs.String("tag", "", "")
+	args, err := parseFlags(fs, cmd.Args, usage)
+	if err != nil {
+		return err
+	}
+	if len(args) != 0 {
+		return fmt.Errorf("Incorrect usage\nTry '%s'\n", usage)
+	}
+	tag, err := tagArg(*tagFlag)
+	if err != nil {
+		return err
+	}
+
+	newGetFollowsParams := database.GetFeedFollowsForUserParams{
+		UserID: user.ID,
+		Tag: tag,
+	}
+	userFollows, err := s.Db.GetFeedFollowsForUser(context.Background(), newGetFollowsParams)
 	if err != nil {
 		return fmt.Errorf("error while fetching follows data from the database - %w\n", err)
 	}
@@ -201,8 +295,18 @@ func cmdFollowing(s *config.State, cmd Command, user database.User) error {
 		fmt.Printf("You don't follow any feed!\n")
 	}
 
-	for _, feed := range userFollows {
-		fmt.Printf("* \"%s\"\n", feed.FeedName)
+	for _, follow := range userFollows {
+		if follow.DisplayName.Valid {
+			fmt.Printf("\"%s\" (%d unread):\n", follow.DisplayName.String, follow.UnreadCount)
+			fmt.Printf(" * feed name: %s\n", follow.Feed.Name)
+		} else {
+			fmt.Printf("\"%s\" (%d unread):\n", follow.Feed.Name, follow.UnreadCount)
+		}
+		fmt.Printf(" * %s\n", follow.Feed.Url)
+		if follow.Tags != "" {
+			fmt.Printf(" * tags: %s\n", strings.ReplaceAll(follow.Tags, ",", ", "))
+		}
+		printFeedMetadata(follow.Feed)
 	}
 	return nil
 }
@@ -212,9 +316,9 @@ func cmdUnfollow(s *config.State, cmd Command, user database.User) error {
 		return fmt.Errorf("Incorrect usage\nTry 'unfollow <feed_url>'\n")
 	}
 
-	feed, err := s.Db.GetFeedByURL(context.Background(), cmd.Args[0])
+	feed, err := getFeedByURL(context.Background(), s, cmd.Args[0])
 	if err != nil {
-		if strings.Contains(err.Error(), "sql: no rows in result set") {
+		if errors.Is(err, database.ErrNotFound) {
 			return fmt.Errorf("given feed does not exist in the database\n")
 		}
 		return fmt.Errorf("error while getting feed from the database - %w\n", err)
@@ -251,6 +355,15 @@ func cmdAgg(s *config.State, cmd Command) error {
 		fmt.Printf("Collecting feeds every %s!\n", cmd.Args[0])
 	}
 
+	var backupInterval time.Duration
+	if s.Conf.Backup.Interval != "" {
+		backupInterval, err = time.ParseDuration(s.Conf.Backup.Interval)
+		if err != nil || backupInterval <= 0 {
+			return fmt.Errorf("incorrect backup interval \"%s\" in the config file\nTry [1h, 24h, 168h, ...]\n", s.Conf.Backup.Interval)
+		}
+		fmt.Printf("Backing the database up every %s!\n", s.Conf.Backup.Interval)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 	sigCh := make(chan os.Signal, 1)
@@ -264,74 +377,312 @@ func cmdAgg(s *config.State, cmd Command) error {
 	defer ticker.Stop()
 	failures := 0
 	for {
+		err := aggregating.ScrapeFeeds(ctx, s)
+		if err != nil && ctx.Err() == nil {
+			failures++
+			if failures >= 3 {
+				log.Printf("\nerror while scraping feeds data - %v\n", err)
+				return fmt.Errorf("Too many consecutive errors, exiting...\n")
+			}
+			log.Printf("\nerror while scraping feeds data - %vTrying again...\n\n", err)
+		} else {
+			failures = 0
+		}
+
+		// backups don't stop aggregating, a failed one is tried again on the next tick
+		if backupInterval != 0 && ctx.Err() == nil {
+			if err := scheduledBackup(ctx, s, backupInterval); err != nil {
+				log.Printf("\nerror while making scheduled backup - %v\n", err)
+			}
+		}
+
 		select {
 		case <-ctx.Done():
 			log.Printf("\nAggregating finished!\n")
 			return nil
 		case <-ticker.C:
-			err := aggregating.ScrapeFeeds(ctx, s)
+		}
+	}
+}
+
+func cmdFetch(s *config.State, cmd Command) error {
+	usage := "fetch [--all] [--interval <duration [default = 30m]>] <(optional) feed_url...>"
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	all := fs.Bool("all", false, "")
+	interval := fs.Duration("interval", 30*time.Minute, "")
+	args, err := parseFlags(fs, cmd.Args, usage)
+	if err != nil {
+		return err
+	}
+	if *all && len(args) != 0 {
+		return fmt.Errorf("Incorrect usage, '--all' cannot be used together with feed URLs\nTry '%s'\n", usage)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	var feeds []database.Feed
+	switch {
+	case len(args) != 0:
+		for _, feedURL := range args {
+			feed, err := getFeedByURL(ctx, s, feedURL)
 			if err != nil {
-				failures++
-				if failures >= 3 {
-					log.Printf("\nerror while scraping feeds data - %v\n", err)
-					return fmt.Errorf("Too many consecutive errors, exiting...\n")
+				if errors.Is(err, database.ErrNotFound) {
+					return fmt.Errorf("feed \"%s\" does not exist in the database\n", feedURL)
 				}
-				log.Printf("\nerror while scraping feeds data - %vTrying again...\n\n", err)
-				continue
+				return fmt.Errorf("error while getting feed from the database - %w\n", err)
 			}
-			failures = 0
+			if feed.ArchivedAt.Valid {
+				return fmt.Errorf("feed \"%s\" has been archived and isn't fetched anymore\n", feed.Name)
+			}
+			feeds = append(feeds, feed)
+		}
+	case *all:
+		feeds, err = s.Db.GetFollowedFeeds(ctx)
+	default:
+		feeds, err = s.Db.GetFeedsDueForFetch(ctx, time.Now().Add(-*interval))
+	}
+	if err != nil {
+		return fmt.Errorf("error while getting feeds to fetch from the database - %w\n", err)
+	}
+
+	if len(feeds) == 0 {
+		fmt.Printf("Nothing to fetch!\n")
+		return nil
+	}
+
+	failed := 0
+	for _, feed := range feeds {
+		res := aggregating.ScrapeFeed(ctx, s, feed)
+		status := res.Status
+		if status == "" {
+			status = "no response"
+		}
+
+		fmt.Printf("\"%s\":\n", feed.Name)
+		fmt.Printf(" * status: %s\n", status)
+		fmt.Printf(" * posts: %d new, %d updated\n", res.NewPosts, res.UpdatedPosts)
+		fmt.Printf(" * took: %s\n", res.Duration.Round(time.Millisecond))
+		if res.Err != nil {
+			failed++
+			fmt.Printf(" * error: %s\n", strings.TrimSpace(res.Err.Error()))
+		}
+
+		if ctx.Err() != nil {
+			return fmt.Errorf("fetching interrupted\n")
 		}
 	}
+
+	if failed != 0 {
+		return fmt.Errorf("%d of %d feeds failed to fetch\n", failed, len(feeds))
+	}
+	fmt.Printf("\nFetched %d feeds!\n", len(feeds))
+	return nil
 }
 
-func cmdBrowse(s *config.State, cmd Command, user database.User) error {
-	if len(cmd.Args) > 2 {
-		return fmt.Errorf("Incorrect usage\nTry 'browse <limit [default = 2]> <(optional) search_query>'\n")
-	}
-
-	postsLimit := 2
-	var feedName string
-	if len(cmd.Args) != 0 {
-		for _, arg := range cmd.Args {
-			if n, err := strconv.Atoi(arg); err != nil {
-				feedName = arg
-			} else {
-				postsLimit = n
-			}
+func cmdCheck(s *config.State, cmd Command) error {
+	usage := "check [--preview <items [default = 3]>] <feed_url>"
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	preview := fs.Int("preview", 3, "")
+	args, err := parseFlags(fs, cmd.Args, usage)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("Incorrect usage\nTry '%s'\n", usage)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	report, err := aggregating.Check(ctx, args[0], *preview)
+
+	valueOr := func(value, fallback string) string {
+		if value == "" {
+			return fallback
+		}
+		return value
+	}
+
+	fmt.Printf("%s:\n", report.URL)
+	for _, redirect := range report.Redirects {
+		fmt.Printf(" * redirect: %s\n", redirect)
+	}
+	if report.FinalURL != "" && report.FinalURL != report.URL {
+		fmt.Printf(" * final URL: %s\n", report.FinalURL)
+	}
+	fmt.Printf(" * status: %s\n", valueOr(report.Status, "no response"))
+	if report.Status != "" {
+		fmt.Printf(" * content type: %s\n", valueOr(report.ContentType, "-"))
+		fmt.Printf(" * caching: etag %s, last-modified %s, cache-control %s, expires %s\n", valueOr(report.ETag, "-"), valueOr(report.LastModified, "-"), valueOr(report.CacheControl, "-"), valueOr(report.Expires, "-"))
+	}
+	if report.Format != "" {
+		fmt.Printf(" * format: %s\n", report.Format)
+		fmt.Printf(" * encoding: %s (header: %s)\n", report.Encoding, valueOr(report.HeaderEncoding, "-"))
+	}
+	if err != nil {
+		return fmt.Errorf("feed check failed - %w", err)
+	}
+
+	fmt.Printf(" * title: %s\n", report.Title)
+	fmt.Printf(" * site: %s\n", valueOr(report.Link, "-"))
+	fmt.Printf(" * items: %d\n", report.Items)
+	fmt.Printf(" * dates parsed: %d/%d\n", report.DatesParsed, report.Items)
+	fmt.Printf(" * missing links: %d, missing GUIDs: %d\n", report.MissingLinks, report.MissingGUIDs)
+
+	for _, it := range report.Preview {
+		published := "unknown date"
+		if it.DateParsed {
+			published = it.PublishedAt.Format(time.DateTime)
 		}
+		fmt.Printf("\n\"%s\":\n", it.Title)
+		fmt.Printf(" * %s\n", valueOr(it.Link, "no link"))
+		fmt.Printf(" * %s\n", published)
 	}
+	return nil
+}
 
-	newGetPostsParams := database.GetPostsForUserParams{
-		ID: user.ID,
-		Limit: int64(postsLimit),
-		FeedQuery: sql.NullString{
-			String: feedName,
+func cmdBrowse(s *config.State, cmd Command, user database.User) error {
+	usage := "browse [--all] [--limit <limit [default = 2]>] [--sort published|fetched] [--asc] [--offset <offset>] [--cursor <cursor>] [--tag <tag>] <(optional) query>"
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	all := fs.Bool("all", false, "")
+	limit := fs.Int("limit", 2, "")
+	sortBy := fs.String("sort", database.SortPublished, "")
+	ascending := fs.Bool("asc", false, "")
+	offset := fs.Int("offset", 0, "")
+	cursor := fs.String("cursor", "", "")
+	tagFlag := fs.String("tag", "", "")
+	args, err := parseFlags(fs, cmd.Args, usage)
+	if err != nil {
+		return err
+	}
+	if *limit < 1 || *offset < 0 {
+		return fmt.Errorf("Incorrect usage - limit has to be positive and offset can't be negative\nTry '%s'\n", usage)
+	}
+	if *sortBy != database.SortPublished && *sortBy != database.SortFetched {
+		return fmt.Errorf("unknown sort order \"%s\", expected published or fetched\n", *sortBy)
+	}
+
+	f, err := querying.Parse(strings.Join(args, " "))
+	if err != nil {
+		return err
+	}
+	if *tagFlag != "" {
+		tag, err := normalizeTag(*tagFlag)
+		if err != nil {
+			return err
+		}
+		f.Tags = append(f.Tags, tag)
+	}
+	if !*all && !f.Read.Valid && !f.Starred {
+		f.Read = sql.NullBool{
+			Bool: false,
 			Valid: true,
-		},
+		}
 	}
-	posts, err := s.Db.GetPostsForUser(context.Background(), newGetPostsParams)
+	if *cursor != "" {
+		if f.Cursor, err = querying.DecodeCursor(*cursor); err != nil {
+			return err
+		}
+	}
+	f.UserID = user.ID
+	f.Sort = *sortBy
+	f.Ascending = *ascending
+	f.Limit = int64(*limit)
+	f.Offset = int64(*offset)
+
+	filters, err := s.Db.GetFiltersForUser(context.Background(), sql.NullString{String: user.ID, Valid: true})
 	if err != nil {
-		return fmt.Errorf("error while fetching posts from the database - %v\n", err)
+		return fmt.Errorf("error while getting filters from the database - %w\n", err)
+	}
+	rules, err := filtering.FromDatabase(filters)
+	if err != nil {
+		return err
+	}
+
+	// hidden posts are skipped, so pages are fetched until there is one more post to show than the limit, which means there is a next page
+	var posts []database.BrowsePostsRow
+	more := false
+	for {
+		page, err := s.Db.BrowsePosts(context.Background(), f)
+		if err != nil {
+			return fmt.Errorf("error while fetching posts from the database - %v\n", err)
+		}
+
+		for _, post := range page {
+			if isHidden(rules, post) {
+				continue
+			}
+			if len(posts) == *limit {
+				more = true
+				break
+			}
+			posts = append(posts, post)
+		}
+		if more || len(page) < *limit {
+			break
+		}
+		f.Cursor = &database.BrowseCursor{
+			SortKey: page[len(page)-1].SortKey,
+			ID: page[len(page)-1].ID,
+		}
+		f.Offset = 0
 	}
 
 	if len(posts) == 0 {
-		fmt.Printf("There is nothing to browse!\n")
+		if f.Read.Valid && !f.Read.Bool && !*all {
+			fmt.Printf("There are no unread posts, try 'browse --all'!\n")
+		} else {
+			fmt.Printf("There is nothing to browse!\n")
+		}
 	}
 
 	for _, post := range posts {
-		fmt.Printf("\"%s\":\n", post.Title)
+		var marks []string
+		if post.IsRead {
+			marks = append(marks, "read")
+		}
+		if post.IsStarred {
+			marks = append(marks, "starred")
+		}
+		if len(marks) != 0 {
+			fmt.Printf("\"%s\" (%s):\n", post.Title, strings.Join(marks, ", "))
+		} else {
+			fmt.Printf("\"%s\":\n", post.Title)
+		}
+		fmt.Printf(" * id: %s\n", shortID(post.ID))
 		fmt.Printf(" * %s\n", post.Description.String)
-		fmt.Printf(" * %s\n\n", post.Url)
+		fmt.Printf(" * %s\n", post.Url)
+		if post.Authors != "" {
+			fmt.Printf(" * by: %s\n", post.Authors)
+		}
+		if categories := listValues(post.Categories); len(categories) != 0 {
+			fmt.Printf(" * categories: %s\n", strings.Join(categories, ", "))
+		}
+		fmt.Printf(" * from: %s\n\n", strings.Join(listValues(post.FeedNames), ", "))
+	}
+
+	if more {
+		fmt.Printf("More posts: --cursor %s\n", querying.EncodeCursor(posts[len(posts)-1]))
 	}
 	return nil
 }
 
-func cmdReset(s *config.State, cmd Command) error {
-	err := s.Db.ResetDb(context.Background())
-	if err != nil {
-		return fmt.Errorf("error while resetting database - %w\n", err)
+// isHidden checks post against filter rules, the same story may come from many feeds and a rule matching any of them hides it
+func isHidden(rules filtering.Rules, post database.BrowsePostsRow) bool {
+	categories := listValues(post.Categories)
+	for _, feedName := range listValues(post.FeedNames) {
+		item := filtering.Item{
+			Feed: feedName,
+			Title: post.Title,
+			Description: post.Description.String,
+			Author: post.Authors,
+			Categories: categories,
+			URL: post.Url,
+		}
+		if rules.Matches(item) {
+			return true
+		}
 	}
 
-	fmt.Printf("Database has been reset!\n")
-	return nil
+	return false
 }
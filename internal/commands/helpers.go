@@ -0,0 +1,142 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"github.com/MedrekIT/gator/internal/backuping"
+	"github.com/MedrekIT/gator/internal/config"
+	"github.com/MedrekIT/gator/internal/database"
+	"github.com/MedrekIT/gator/internal/rewriting"
+)
+
+// parseFlags parses flags placed anywhere between command arguments and returns the positional ones
+func parseFlags(fs *flag.FlagSet, args []string, usage string) ([]string, error) {
+	fs.SetOutput(io.Discard)
+
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, fmt.Errorf("Incorrect usage - %v\nTry '%s'\n", err, usage)
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	return positional, nil
+}
+
+// confirm asks given yes/no question on standard input, anything but "y" or "yes" is taken as no
+func confirm(question string) (bool, error) {
+	fmt.Printf("%s [y/N]: ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, fmt.Errorf("error while reading answer - %w\n", err)
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+// backupDb backs the database up before a destructive operation, the backup is named after the operation
+func backupDb(ctx context.Context, s *config.State, operation string) error {
+	path, err := backuping.Snapshot(ctx, s.DbConn, backuping.Dir(s.Conf.DbPath, s.Conf.Backup), operation)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Database has been backed up to %s!\n", path)
+	return nil
+}
+
+// shortID shortens UUIDs for display, every command taking an ID accepts such prefix
+func shortID(id string) string {
+	if len(id) < 8 {
+		return id
+	}
+	return id[:8]
+}
+
+// listValues decodes lists aggregated by queries with json_group_array, values such as feed names or categories may contain commas themselves
+func listValues(list string) []string {
+	var values []string
+	if err := json.Unmarshal([]byte(list), &values); err != nil {
+		return nil
+	}
+	return values
+}
+
+// getFeedByURL normalizes given URL and looks the feed up under both http and https schemes, falling back to the URL exactly as given
+func getFeedByURL(ctx context.Context, s *config.State, rawURL string) (database.Feed, error) {
+	normalized, err := rewriting.NormalizeFeedURL(rawURL)
+	if err != nil {
+		return database.Feed{}, err
+	}
+
+	var feed database.Feed
+	for _, feedURL := range append(rewriting.FeedURLVariants(normalized), rawURL) {
+		feed, err = s.Db.GetFeedByURL(ctx, feedURL)
+		if err == nil || !errors.Is(err, database.ErrNotFound) {
+			return feed, err
+		}
+	}
+
+	return database.Feed{}, err
+}
+
+// printFeedMetadata prints channel details known from the last fetch, skipping missing ones
+func printFeedMetadata(feed database.Feed) {
+	if feed.SiteLink.Valid {
+		fmt.Printf(" * site: %s\n", feed.SiteLink.String)
+	}
+	if feed.Description.Valid {
+		fmt.Printf(" * description: %s\n", feed.Description.String)
+	}
+	if feed.Language.Valid {
+		fmt.Printf(" * language: %s\n", feed.Language.String)
+	}
+	if feed.ImageUrl.Valid {
+		fmt.Printf(" * image: %s\n", feed.ImageUrl.String)
+	}
+	if feed.Generator.Valid {
+		fmt.Printf(" * generator: %s\n", feed.Generator.String)
+	}
+	if feed.LastBuildDate.Valid {
+		fmt.Printf(" * last built: %s\n", feed.LastBuildDate.Time.Local().Format(time.DateTime))
+	}
+}
+
+// scheduledBackup backs the database up when the last scheduled backup is older than interval and rotates old ones
+func scheduledBackup(ctx context.Context, s *config.State, interval time.Duration) error {
+	dir := backuping.Dir(s.Conf.DbPath, s.Conf.Backup)
+	due, err := backuping.Due(dir, interval)
+	if err != nil || !due {
+		return err
+	}
+
+	keep := s.Conf.Backup.Keep
+	if keep <= 0 {
+		keep = backuping.DefaultKeep
+	}
+	path, removed, err := backuping.Scheduled(ctx, s.DbConn, dir, keep)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Database has been backed up to %s!\n", path)
+	for _, old := range removed {
+		fmt.Printf("Old backup %s has been removed!\n", old)
+	}
+	return nil
+}
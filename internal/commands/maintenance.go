@@ -0,0 +1,484 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+	"github.com/MedrekIT/gator/internal/backuping"
+	"github.com/MedrekIT/gator/internal/config"
+	"github.com/MedrekIT/gator/internal/connecting"
+	"github.com/MedrekIT/gator/internal/database"
+	"github.com/MedrekIT/gator/internal/querying"
+	"github.com/MedrekIT/gator/internal/rewriting"
+	"github.com/MedrekIT/gator/sql"
+)
+
+func cmdMergeFeeds(s *config.State, cmd Command) error {
+	usage := "mergefeeds [--dry-run]"
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "")
+	args, err := parseFlags(fs, cmd.Args, usage)
+	if err != nil {
+		return err
+	}
+	if len(args) != 0 {
+		return fmt.Errorf("Incorrect usage\nTry '%s'\n", usage)
+	}
+
+	ctx := context.Background()
+	feeds, err := s.Db.GetFeeds(ctx)
+	if err != nil {
+		return fmt.Errorf("error while getting feeds from the database - %w\n", err)
+	}
+
+	groups := map[string][]database.Feed{}
+	var keys []string
+	for _, feed := range feeds {
+		key, err := rewriting.FeedURLKey(feed.Url)
+		if err != nil {
+			fmt.Printf("Skipping feed \"%s\" - %v", feed.Name, err)
+			continue
+		}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], feed)
+	}
+
+	merged := 0
+	for _, key := range keys {
+		group := groups[key]
+		sort.Slice(group, func(i, j int) bool {
+			return group[i].CreatedAt.Before(group[j].CreatedAt)
+		})
+		keep := group[0]
+
+		// the oldest feed survives, keeping the scheme it has been fetched with so far
+		targetURL, err := rewriting.NormalizeFeedURL(keep.Url)
+		if err != nil {
+			return err
+		}
+
+		for _, dup := range group[1:] {
+			fmt.Printf("Merging \"%s\" (%s) into \"%s\" (%s)\n", dup.Name, dup.Url, keep.Name, keep.Url)
+			merged++
+			if *dryRun {
+				continue
+			}
+			if err := mergeFeed(ctx, s, keep, dup); err != nil {
+				return err
+			}
+		}
+
+		if keep.Url != targetURL {
+			fmt.Printf("Normalizing URL of \"%s\": %s -> %s\n", keep.Name, keep.Url, targetURL)
+			if *dryRun {
+				continue
+			}
+			newUpdateFeedParams := database.UpdateFeedURLParams{
+				ID: keep.ID,
+				Url: targetURL,
+				UpdatedAt: time.Now(),
+			}
+			_, err = s.Db.UpdateFeedURL(ctx, newUpdateFeedParams)
+			if err != nil {
+				return fmt.Errorf("error while updating feed URL in the database - %w\n", err)
+			}
+		}
+	}
+
+	if merged == 0 {
+		fmt.Printf("No duplicated feeds found!\n")
+	} else if *dryRun {
+		fmt.Printf("%d feeds would be merged!\n", merged)
+	} else {
+		fmt.Printf("%d feeds have been merged!\n", merged)
+	}
+	return nil
+}
+
+// mergeFeed moves follows and posts of duplicated feed onto kept one and removes the duplicate, all in a single transaction
+func mergeFeed(ctx context.Context, s *config.State, keep, dup database.Feed) error {
+	return s.Db.InTx(ctx, func(db *database.Store) error {
+		err := db.MoveFeedFollows(ctx, database.MoveFeedFollowsParams{
+			ToFeedID: keep.ID,
+			FromFeedID: dup.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("error while moving feed follows - %w\n", err)
+		}
+		// follows left behind belong to users who already followed both feeds, their tags and names are kept on the remaining follow
+		err = db.MoveFollowTags(ctx, database.MoveFollowTagsParams{
+			ToFeedID: keep.ID,
+			FromFeedID: dup.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("error while moving follow tags - %w\n", err)
+		}
+		err = db.MoveFollowDisplayNames(ctx, database.MoveFollowDisplayNamesParams{
+			FromFeedID: dup.ID,
+			ToFeedID: keep.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("error while moving feed follow names - %w\n", err)
+		}
+		_, err = db.DeleteFeedFollowsForFeed(ctx, dup.ID)
+		if err != nil {
+			return fmt.Errorf("error while removing feed follows - %w\n", err)
+		}
+
+		_, err = db.MovePosts(ctx, database.MovePostsParams{
+			ToFeedID: keep.ID,
+			FromFeedID: dup.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("error while moving feed posts - %w\n", err)
+		}
+		err = db.MovePostSources(ctx, database.MovePostSourcesParams{
+			ToFeedID: keep.ID,
+			FromFeedID: dup.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("error while moving feed posts - %w\n", err)
+		}
+		err = db.DeletePostSourcesForFeed(ctx, dup.ID)
+		if err != nil {
+			return fmt.Errorf("error while removing post sources - %w\n", err)
+		}
+
+		err = db.DeleteFeed(ctx, dup.ID)
+		if err != nil {
+			return fmt.Errorf("error while removing feed from the database - %w\n", err)
+		}
+		return nil
+	})
+}
+
+// cmdPrune removes posts published before given date, posts starred by any user are always kept,
+// removed posts aren't saved again when their feeds are fetched
+func cmdPrune(s *config.State, cmd Command) error {
+	usage := "prune --before <date [YYYY-MM-DD, ...]>"
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	beforeArg := fs.String("before", "", "")
+	args, err := parseFlags(fs, cmd.Args, usage)
+	if err != nil {
+		return err
+	}
+	if len(args) != 0 || *beforeArg == "" {
+		return fmt.Errorf("Incorrect usage\nTry '%s'\n", usage)
+	}
+
+	before, err := querying.ParseDate(*beforeArg)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if err := backupDb(ctx, s, "prune"); err != nil {
+		return err
+	}
+	var removed int64
+	err = s.Db.InTx(ctx, func(db *database.Store) error {
+		// links are remembered first, so feeds still listing the posts don't bring them back
+		err := db.MarkPostsPrunedBefore(ctx, database.MarkPostsPrunedBeforeParams{
+			PrunedAt: time.Now(),
+			Before: before,
+		})
+		if err != nil {
+			return fmt.Errorf("error while saving pruned posts - %w\n", err)
+		}
+		removed, err = db.DeletePostsBefore(ctx, before)
+		if err != nil {
+			return fmt.Errorf("error while removing posts from the database - %w\n", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%d posts published before %s have been removed, starred posts were kept!\n", removed, *beforeArg)
+	return nil
+}
+
+// cmdGC removes feeds nobody follows together with their posts, once they haven't been fetched for the grace period,
+// the scheduler stops fetching feeds nobody follows, so the period starts roughly when the last follower leaves,
+// feeds with starred posts are archived instead, keeping only the starred ones, and left alone once nothing else remains
+func cmdGC(s *config.State, cmd Command) error {
+	usage := "gc [--grace <duration [default = 720h]>] [--dry-run]"
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	grace := fs.Duration("grace", 30*24*time.Hour, "")
+	dryRun := fs.Bool("dry-run", false, "")
+	args, err := parseFlags(fs, cmd.Args, usage)
+	if err != nil {
+		return err
+	}
+	if len(args) != 0 || *grace < 0 {
+		return fmt.Errorf("Incorrect usage\nTry '%s'\n", usage)
+	}
+
+	ctx := context.Background()
+	orphaned, err := s.Db.GetOrphanedFeeds(ctx, time.Now().Add(-*grace))
+	if err != nil {
+		return fmt.Errorf("error while getting feeds from the database - %w\n", err)
+	}
+
+	var feeds []database.Feed
+	var removed, archived int
+	for _, feed := range orphaned {
+		starred, err := s.Db.CountStarredPostsOfFeed(ctx, feed.ID)
+		if err != nil {
+			return fmt.Errorf("error while counting starred posts - %w\n", err)
+		}
+		if starred == 0 {
+			if *dryRun {
+				fmt.Printf("Feed \"%s\" (%s) would be removed\n", feed.Name, feed.Url)
+			}
+			feeds = append(feeds, feed)
+			removed++
+			continue
+		}
+
+		posts, err := s.Db.CountPostsOfFeed(ctx, feed.ID)
+		if err != nil {
+			return fmt.Errorf("error while counting feed posts - %w\n", err)
+		}
+		// feeds archived by an earlier collection only hold starred posts
+		if feed.ArchivedAt.Valid && posts == starred {
+			continue
+		}
+		if *dryRun {
+			fmt.Printf("Feed \"%s\" (%s) would be archived to keep %d starred posts, %d other posts would be removed\n", feed.Name, feed.Url, starred, posts-starred)
+		}
+		feeds = append(feeds, feed)
+		archived++
+	}
+
+	if len(feeds) == 0 {
+		fmt.Printf("No feeds to collect!\n")
+		return nil
+	}
+	if *dryRun {
+		fmt.Printf("%d feeds would be removed and %d archived to keep starred posts!\n", removed, archived)
+		return nil
+	}
+
+	if err := backupDb(ctx, s, "gc"); err != nil {
+		return err
+	}
+	for _, feed := range feeds {
+		if err := removeFeed(ctx, s, feed, true); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("%d feeds nobody follows have been removed and %d archived to keep starred posts!\n", removed, archived)
+	return nil
+}
+
+// cmdMigrate shows and changes version of the database schema, other commands only migrate a fresh database up on their own,
+// moving the schema down drops tables and columns, so it asks first and backs the database up
+func cmdMigrate(s *config.State, cmd Command) error {
+	usage := "migrate [--yes] status | up | down | to <version> | redo"
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	yes := fs.Bool("yes", false, "")
+	args, err := parseFlags(fs, cmd.Args, usage)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("Incorrect usage\nTry '%s'\n", usage)
+	}
+
+	ctx := context.Background()
+	current, _, err := embedding.Versions(ctx, s.DbConn)
+	if err != nil {
+		return err
+	}
+
+	var version int64
+	question := ""
+	switch {
+	case args[0] == "status" && len(args) == 1:
+		return migrationStatus(ctx, s)
+	case args[0] == "up" && len(args) == 1:
+	case args[0] == "down" && len(args) == 1 && current != 0:
+		question = fmt.Sprintf("Roll back migration %d, removing tables and columns it added together with their data?", current)
+	case args[0] == "redo" && len(args) == 1 && current != 0:
+		question = fmt.Sprintf("Roll back and apply again migration %d, removing data of tables and columns it added?", current)
+	case (args[0] == "down" || args[0] == "redo") && len(args) == 1:
+		// nothing to roll back, the migrating function reports it
+	case args[0] == "to" && len(args) == 2:
+		version, err = strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("incorrect version \"%s\"\nTry '%s'\n", args[1], usage)
+		}
+		if version == current-1 {
+			question = fmt.Sprintf("Roll back migration %d, removing tables and columns it added together with their data?", current)
+		} else if version < current {
+			question = fmt.Sprintf("Roll back migrations %d-%d, removing tables and columns they added together with their data?", version+1, current)
+		}
+	default:
+		return fmt.Errorf("Incorrect usage\nTry '%s'\n", usage)
+	}
+
+	if question != "" {
+		if !*yes {
+			ok, err := confirm(question)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				fmt.Printf("Database schema has been kept at version %d!\n", current)
+				return nil
+			}
+		}
+		if err := backupDb(ctx, s, "migrate-"+args[0]); err != nil {
+			return err
+		}
+	}
+
+	var steps []embedding.Step
+	switch args[0] {
+	case "up":
+		steps, err = embedding.Up(ctx, s.DbConn)
+	case "down":
+		steps, err = embedding.Down(ctx, s.DbConn)
+	case "to":
+		steps, err = embedding.To(ctx, s.DbConn, version)
+	case "redo":
+		steps, err = embedding.Redo(ctx, s.DbConn)
+	}
+
+	for _, step := range steps {
+		if step.Up {
+			fmt.Printf("Applied %s (%s)\n", step.Name, step.Duration.Round(time.Millisecond))
+		} else {
+			fmt.Printf("Rolled back %s (%s)\n", step.Name, step.Duration.Round(time.Millisecond))
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	current, _, err = embedding.Versions(ctx, s.DbConn)
+	if err != nil {
+		return err
+	}
+	if len(steps) == 0 {
+		fmt.Printf("Database schema is already at version %d!\n", current)
+	} else {
+		fmt.Printf("Database schema is now at version %d!\n", current)
+	}
+	return nil
+}
+
+func migrationStatus(ctx context.Context, s *config.State) error {
+	migrations, err := embedding.Status(ctx, s.DbConn)
+	if err != nil {
+		return err
+	}
+	current, latest, err := embedding.Versions(ctx, s.DbConn)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if m.Applied {
+			fmt.Printf(" * %s - applied %s\n", m.Name, m.AppliedAt.Local().Format(time.DateTime))
+		} else {
+			fmt.Printf(" * %s - pending\n", m.Name)
+		}
+	}
+	fmt.Printf("Database schema is at version %d, the latest version known to this gator is %d\n", current, latest)
+	if current > latest {
+		fmt.Printf("The database has been migrated by a newer gator, update gator to use it\n")
+	}
+	return nil
+}
+
+func cmdBackup(s *config.State, cmd Command) error {
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("Incorrect usage\nTry 'backup <path>'\n")
+	}
+
+	// backups into a directory are named like automatic ones
+	ctx := context.Background()
+	path := cmd.Args[0]
+	var err error
+	if info, statErr := os.Stat(path); statErr == nil && info.IsDir() {
+		path, err = backuping.Snapshot(ctx, s.DbConn, path, "manual")
+	} else {
+		err = backuping.Backup(ctx, s.DbConn, path)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Database has been backed up to %s!\n", path)
+	return nil
+}
+
+// cmdRestore replaces the database with given backup, the current database is backed up first and older backups are migrated up
+func cmdRestore(s *config.State, cmd Command) error {
+	usage := "restore [--yes] <path>"
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	yes := fs.Bool("yes", false, "")
+	args, err := parseFlags(fs, cmd.Args, usage)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("Incorrect usage\nTry '%s'\n", usage)
+	}
+
+	ctx := context.Background()
+	version, err := backuping.Version(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	_, latest, err := embedding.Versions(ctx, s.DbConn)
+	if err != nil {
+		return err
+	}
+	if version > latest {
+		return fmt.Errorf("backup has schema version %d, which is newer than the latest version known to this gator (%d), update gator to restore it\n", version, latest)
+	}
+
+	if !*yes {
+		ok, err := confirm(fmt.Sprintf("Replace all saved data with backup \"%s\"?", args[0]))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Printf("Nothing has been restored!\n")
+			return nil
+		}
+	}
+	if err := backupDb(ctx, s, "restore"); err != nil {
+		return err
+	}
+
+	if err := backuping.Restore(ctx, s.DbConn, args[0]); err != nil {
+		return err
+	}
+	// backups made by older gator are brought up to date at once
+	schemaDb, err := connecting.Open(s.Conf.DbPath, s.Conf.Database, connecting.Schema)
+	if err != nil {
+		return err
+	}
+	defer schemaDb.Close()
+	if _, err := embedding.Up(ctx, schemaDb); err != nil {
+		return err
+	}
+	if err := embedding.DbEmbedding(schemaDb); err != nil {
+		return err
+	}
+
+	fmt.Printf("Database has been restored from %s!\n", args[0])
+	return nil
+}
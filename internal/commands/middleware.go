@@ -2,7 +2,7 @@ package commands
 
 import (
 	"context"
-	"strings"
+	"errors"
 	"fmt"
 	"github.com/MedrekIT/gator/internal/database"
 	"github.com/MedrekIT/gator/internal/config"
@@ -12,7 +12,7 @@ func middlewareLoggedIn(handler func(s *config.State, cmd Command, user database
 	return func(s *config.State, cmd Command) error {
 		user, err := s.Db.GetUser(context.Background(), s.Conf.CurrentUserName)
 		if err != nil {
-			if strings.Contains(err.Error(), "sql: no rows in result set") {
+			if errors.Is(err, database.ErrNotFound) {
 				return fmt.Errorf("user with given name doesn't exist in the database\n")
 			}
 			return fmt.Errorf("error while getting user from the database - %w\n", err)
@@ -0,0 +1,160 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+	"github.com/MedrekIT/gator/internal/config"
+	"github.com/MedrekIT/gator/internal/database"
+	"github.com/MedrekIT/gator/internal/querying"
+)
+
+func cmdRead(s *config.State, cmd Command, user database.User) error {
+	return markPosts(s, cmd, user, true)
+}
+
+func cmdUnread(s *config.State, cmd Command, user database.User) error {
+	return markPosts(s, cmd, user, false)
+}
+
+// markPosts marks given posts, every post of a feed or tag or every post published before a date as read or unread, unread posts are always unmarked as a whole story
+func markPosts(s *config.State, cmd Command, user database.User, read bool) error {
+	usage := fmt.Sprintf("%s [--feed <feed_url>] [--tag <tag>] [--before <date [YYYY-MM-DD, ...]>] <(optional) post_id...>", cmd.Name)
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	feedURL := fs.String("feed", "", "")
+	tagFlag := fs.String("tag", "", "")
+	beforeArg := fs.String("before", "", "")
+	args, err := parseFlags(fs, cmd.Args, usage)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 && *feedURL == "" && *tagFlag == "" && *beforeArg == "" {
+		return fmt.Errorf("Incorrect usage\nTry '%s'\n", usage)
+	}
+
+	ctx := context.Background()
+	var marked int64
+	for _, idPrefix := range args {
+		post, err := getPostByIDPrefix(ctx, s, idPrefix)
+		if err != nil {
+			return err
+		}
+
+		var n int64
+		if read {
+			n, err = s.Db.MarkPostRead(ctx, database.MarkPostReadParams{
+				UserID: user.ID,
+				PostID: post.ID,
+				ReadAt: time.Now(),
+			})
+		} else {
+			n, err = s.Db.MarkPostUnread(ctx, database.MarkPostUnreadParams{
+				UserID: user.ID,
+				ID: post.ID,
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("error while marking post %s - %w\n", shortID(post.ID), err)
+		}
+		marked += n
+	}
+
+	var feeds []database.Feed
+	if *feedURL != "" {
+		feed, err := getFeedByURL(ctx, s, *feedURL)
+		if err != nil {
+			if errors.Is(err, database.ErrNotFound) {
+				return fmt.Errorf("given feed does not exist in the database\n")
+			}
+			return fmt.Errorf("error while getting feed from the database - %w\n", err)
+		}
+		feeds = append(feeds, feed)
+	}
+	if *tagFlag != "" {
+		tag, err := normalizeTag(*tagFlag)
+		if err != nil {
+			return err
+		}
+		tagged, err := s.Db.GetFeedsForTag(ctx, database.GetFeedsForTagParams{
+			UserID: user.ID,
+			Tag: tag,
+		})
+		if err != nil {
+			return fmt.Errorf("error while getting feeds tagged with \"%s\" from the database - %w\n", tag, err)
+		}
+		if len(tagged) == 0 {
+			return fmt.Errorf("you don't have any feeds tagged with \"%s\"\n", tag)
+		}
+		feeds = append(feeds, tagged...)
+	}
+
+	for _, feed := range feeds {
+		var n int64
+		if read {
+			n, err = s.Db.MarkFeedRead(ctx, database.MarkFeedReadParams{
+				UserID: user.ID,
+				FeedID: feed.ID,
+				ReadAt: time.Now(),
+			})
+		} else {
+			n, err = s.Db.MarkFeedUnread(ctx, database.MarkFeedUnreadParams{
+				UserID: user.ID,
+				FeedID: feed.ID,
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("error while marking posts of \"%s\" - %w\n", feed.Name, err)
+		}
+		marked += n
+	}
+
+	if *beforeArg != "" {
+		before, err := querying.ParseDate(*beforeArg)
+		if err != nil {
+			return err
+		}
+
+		var n int64
+		if read {
+			n, err = s.Db.MarkReadBefore(ctx, database.MarkReadBeforeParams{
+				UserID: user.ID,
+				Before: before,
+				ReadAt: time.Now(),
+			})
+		} else {
+			n, err = s.Db.MarkUnreadBefore(ctx, database.MarkUnreadBeforeParams{
+				UserID: user.ID,
+				Before: before,
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("error while marking posts published before %s - %w\n", *beforeArg, err)
+		}
+		marked += n
+	}
+
+	state := "read"
+	if !read {
+		state = "unread"
+	}
+	fmt.Printf("%d posts have been marked as %s!\n", marked, state)
+	return nil
+}
+
+// getPostByIDPrefix finds post by its ID or a unique prefix of it
+func getPostByIDPrefix(ctx context.Context, s *config.State, idPrefix string) (database.Post, error) {
+	posts, err := s.Db.GetPostsByIDPrefix(ctx, idPrefix)
+	if err != nil {
+		return database.Post{}, fmt.Errorf("error while getting post from the database - %w\n", err)
+	}
+	if len(posts) == 0 {
+		return database.Post{}, fmt.Errorf("post with ID %s does not exist\n", idPrefix)
+	}
+	if len(posts) > 1 {
+		return database.Post{}, fmt.Errorf("ID %s matches more than one post, try using a longer part of it\n", idPrefix)
+	}
+
+	return posts[0], nil
+}
@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+	"github.com/MedrekIT/gator/internal/config"
+	"github.com/MedrekIT/gator/internal/database"
+	"github.com/MedrekIT/gator/internal/querying"
+)
+
+// snippetTagRegexp matches HTML tags in snippets, including ones cut in half at snippet edges
+var snippetTagRegexp = regexp.MustCompile(`<[^>]*>|^[^<]*?>|<[^>]*$`)
+
+func cmdSearch(s *config.State, cmd Command, user database.User) error {
+	usage := "search [--feed <feed_url>] [--tag <tag>] [--after <date>] [--before <date>] [--limit <limit [default = 10]>] <query>"
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	feedURL := fs.String("feed", "", "")
+	tagFlag := fs.String("tag", "", "")
+	afterArg := fs.String("after", "", "")
+	beforeArg := fs.String("before", "", "")
+	limit := fs.Int("limit", 10, "")
+	args, err := parseFlags(fs, cmd.Args, usage)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("Incorrect usage\nTry '%s'\n", usage)
+	}
+
+	ctx := context.Background()
+	available, err := s.Db.SearchAvailable(ctx)
+	if err != nil {
+		return fmt.Errorf("error while checking search index - %w\n", err)
+	}
+	if !available {
+		return fmt.Errorf("full-text search is not available in this build, build gator with 'go build -tags sqlite_fts5'\n")
+	}
+
+	// matches are printed in bold on terminals and marked with asterisks when output is redirected
+	highlightStart, highlightEnd := "*", "*"
+	if info, err := os.Stdout.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		highlightStart, highlightEnd = "\x1b[1m", "\x1b[0m"
+	}
+	newSearchParams := database.SearchPostsParams{
+		Query: strings.Join(args, " "),
+		HighlightStart: highlightStart,
+		HighlightEnd: highlightEnd,
+		UserID: user.ID,
+		Limit: int64(*limit),
+	}
+	if *feedURL != "" {
+		_, feed, err := getFollow(ctx, s, user, *feedURL)
+		if err != nil {
+			return err
+		}
+		newSearchParams.FeedID = sql.NullString{
+			String: feed.ID,
+			Valid: true,
+		}
+	}
+	if newSearchParams.Tag, err = tagArg(*tagFlag); err != nil {
+		return err
+	}
+	if *afterArg != "" {
+		after, err := querying.ParseDate(*afterArg)
+		if err != nil {
+			return err
+		}
+		newSearchParams.After = sql.NullTime{
+			Time: after,
+			Valid: true,
+		}
+	}
+	if *beforeArg != "" {
+		before, err := querying.ParseDate(*beforeArg)
+		if err != nil {
+			return err
+		}
+		newSearchParams.Before = sql.NullTime{
+			Time: before,
+			Valid: true,
+		}
+	}
+
+	results, err := s.Db.SearchPosts(ctx, newSearchParams)
+	if err != nil {
+		if errors.Is(err, database.ErrInvalidSearch) {
+			return fmt.Errorf("incorrect search query - %v\nUse words, \"phrases\", prefix* and AND, OR, NOT operators\n", err)
+		}
+		return fmt.Errorf("error while searching posts - %w\n", err)
+	}
+
+	if len(results) == 0 {
+		fmt.Printf("Nothing matches your query!\n")
+	}
+	for _, post := range results {
+		fmt.Printf("\"%s\":\n", post.Title)
+		fmt.Printf(" * id: %s\n", shortID(post.ID))
+		fmt.Printf(" * %s\n", cleanSnippet(post.Snippet))
+		fmt.Printf(" * %s\n", post.Url)
+		if post.PublishedAt.Valid {
+			fmt.Printf(" * published: %s\n", post.PublishedAt.Time.Local().Format(time.DateTime))
+		}
+		fmt.Printf(" * from: %s\n\n", strings.Join(listValues(post.FeedNames), ", "))
+	}
+	return nil
+}
+
+// cleanSnippet strips markup from snippet, which is cut from raw description, and puts it on a single line
+func cleanSnippet(snippet string) string {
+	snippet = snippetTagRegexp.ReplaceAllString(snippet, " ")
+	return strings.Join(strings.Fields(snippet), " ")
+}
@@ -0,0 +1,165 @@
+package commands
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"github.com/MedrekIT/gator/internal/config"
+	"github.com/MedrekIT/gator/internal/database"
+)
+
+// starredPost is the exported form of a starred post
+type starredPost struct {
+	Title string `json:"title"`
+	URL string `json:"url"`
+	Description string `json:"description,omitempty"`
+	Feeds []string `json:"feeds"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	StarredAt time.Time `json:"starred_at"`
+}
+
+func cmdStar(s *config.State, cmd Command, user database.User) error {
+	return starPosts(s, cmd, user, true)
+}
+
+func cmdUnstar(s *config.State, cmd Command, user database.User) error {
+	return starPosts(s, cmd, user, false)
+}
+
+func starPosts(s *config.State, cmd Command, user database.User, star bool) error {
+	if len(cmd.Args) == 0 {
+		return fmt.Errorf("Incorrect usage\nTry '%s <post_id...>'\n", cmd.Name)
+	}
+
+	ctx := context.Background()
+	for _, idPrefix := range cmd.Args {
+		post, err := getPostByIDPrefix(ctx, s, idPrefix)
+		if err != nil {
+			return err
+		}
+
+		if star {
+			_, err = s.Db.StarPost(ctx, database.StarPostParams{
+				UserID: user.ID,
+				PostID: post.ID,
+				StarredAt: time.Now(),
+			})
+		} else {
+			_, err = s.Db.UnstarPost(ctx, database.UnstarPostParams{
+				UserID: user.ID,
+				PostID: post.ID,
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("error while updating star of post %s - %w\n", shortID(post.ID), err)
+		}
+
+		if star {
+			fmt.Printf("Post \"%s\" has been starred!\n", post.Title)
+		} else {
+			fmt.Printf("Post \"%s\" has been unstarred!\n", post.Title)
+		}
+	}
+	return nil
+}
+
+func cmdStarred(s *config.State, cmd Command, user database.User) error {
+	usage := "starred [--export json|csv]"
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	format := fs.String("export", "", "")
+	args, err := parseFlags(fs, cmd.Args, usage)
+	if err != nil {
+		return err
+	}
+	if len(args) != 0 {
+		return fmt.Errorf("Incorrect usage\nTry '%s'\n", usage)
+	}
+
+	posts, err := s.Db.GetStarredPostsForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("error while fetching starred posts from the database - %w\n", err)
+	}
+
+	switch *format {
+	case "":
+	case "json":
+		return exportJSON(posts)
+	case "csv":
+		return exportCSV(posts)
+	default:
+		return fmt.Errorf("unknown export format \"%s\", use json or csv\n", *format)
+	}
+
+	if len(posts) == 0 {
+		fmt.Printf("You don't have any starred posts!\n")
+	}
+	for _, post := range posts {
+		fmt.Printf("\"%s\":\n", post.Title)
+		fmt.Printf(" * id: %s\n", shortID(post.ID))
+		fmt.Printf(" * %s\n", post.Description.String)
+		fmt.Printf(" * %s\n", post.Url)
+		fmt.Printf(" * from: %s\n", strings.Join(listValues(post.FeedNames), ", "))
+		fmt.Printf(" * starred: %s\n\n", post.StarredAt.Local().Format(time.DateTime))
+	}
+	return nil
+}
+
+func toStarredPost(post database.GetStarredPostsForUserRow) starredPost {
+	exported := starredPost{
+		Title: post.Title,
+		URL: post.Url,
+		Description: post.Description.String,
+		Feeds: listValues(post.FeedNames),
+		StarredAt: post.StarredAt,
+	}
+	if post.PublishedAt.Valid {
+		exported.PublishedAt = &post.PublishedAt.Time
+	}
+
+	return exported
+}
+
+func exportJSON(posts []database.GetStarredPostsForUserRow) error {
+	exported := make([]starredPost, 0, len(posts))
+	for _, post := range posts {
+		exported = append(exported, toStarredPost(post))
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(exported); err != nil {
+		return fmt.Errorf("error while exporting starred posts - %w\n", err)
+	}
+	return nil
+}
+
+func exportCSV(posts []database.GetStarredPostsForUserRow) error {
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{"title", "url", "description", "feeds", "published_at", "starred_at"})
+	for _, post := range posts {
+		exported := toStarredPost(post)
+		publishedAt := ""
+		if exported.PublishedAt != nil {
+			publishedAt = exported.PublishedAt.Format(time.RFC3339)
+		}
+		w.Write([]string{
+			exported.Title,
+			exported.URL,
+			exported.Description,
+			strings.Join(exported.Feeds, ", "),
+			publishedAt,
+			exported.StarredAt.Format(time.RFC3339),
+		})
+	}
+	w.Flush()
+
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("error while exporting starred posts - %w\n", err)
+	}
+	return nil
+}
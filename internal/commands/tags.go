@@ -0,0 +1,178 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+	"github.com/MedrekIT/gator/internal/config"
+	"github.com/MedrekIT/gator/internal/database"
+)
+
+func cmdTag(s *config.State, cmd Command, user database.User) error {
+	return tagFollow(s, cmd, user, true)
+}
+
+func cmdUntag(s *config.State, cmd Command, user database.User) error {
+	return tagFollow(s, cmd, user, false)
+}
+
+// tagFollow adds or removes tags of a followed feed, tags are personal so the same feed may be tagged differently by every user
+func tagFollow(s *config.State, cmd Command, user database.User, add bool) error {
+	if len(cmd.Args) < 2 {
+		return fmt.Errorf("Incorrect usage\nTry '%s <feed_url> <tag...>'\n", cmd.Name)
+	}
+
+	ctx := context.Background()
+	follow, feed, err := getFollow(ctx, s, user, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+
+	for _, arg := range cmd.Args[1:] {
+		tag, err := normalizeTag(arg)
+		if err != nil {
+			return err
+		}
+
+		if add {
+			_, err = s.Db.AddFollowTag(ctx, database.AddFollowTagParams{
+				FollowID: follow.ID,
+				Tag: tag,
+				CreatedAt: time.Now(),
+			})
+		} else {
+			_, err = s.Db.RemoveFollowTag(ctx, database.RemoveFollowTagParams{
+				FollowID: follow.ID,
+				Tag: tag,
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("error while updating tags of \"%s\" - %w\n", feed.Name, err)
+		}
+
+		if add {
+			fmt.Printf("Feed \"%s\" has been tagged with \"%s\"!\n", feed.Name, tag)
+		} else {
+			fmt.Printf("Tag \"%s\" has been removed from feed \"%s\"!\n", tag, feed.Name)
+		}
+	}
+	return nil
+}
+
+func cmdTags(s *config.State, cmd Command, user database.User) error {
+	tags, err := s.Db.GetTagsForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("error while getting tags from the database - %w\n", err)
+	}
+
+	if len(tags) == 0 {
+		fmt.Printf("You don't have any tags!\n")
+	}
+	for _, t := range tags {
+		fmt.Printf(" * %s (%d feeds)\n", t.Tag, t.FeedsCount)
+	}
+	return nil
+}
+
+// cmdRename sets name of a followed feed seen only by current user, the name given by feed's creator is used again after --reset
+func cmdRename(s *config.State, cmd Command, user database.User) error {
+	usage := "rename [--reset] <feed_url> <name>"
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	reset := fs.Bool("reset", false, "")
+	args, err := parseFlags(fs, cmd.Args, usage)
+	if err != nil {
+		return err
+	}
+	if (*reset && len(args) != 1) || (!*reset && len(args) != 2) {
+		return fmt.Errorf("Incorrect usage\nTry '%s'\n", usage)
+	}
+
+	ctx := context.Background()
+	follow, feed, err := getFollow(ctx, s, user, args[0])
+	if err != nil {
+		return err
+	}
+
+	var displayName sql.NullString
+	if !*reset {
+		name := strings.TrimSpace(args[1])
+		if name == "" {
+			return fmt.Errorf("feed name can't be empty\n")
+		}
+		displayName = sql.NullString{
+			String: name,
+			Valid: true,
+		}
+	}
+
+	newRenameParams := database.RenameFeedFollowParams{
+		ID: follow.ID,
+		DisplayName: displayName,
+		UpdatedAt: time.Now(),
+	}
+	err = s.Db.RenameFeedFollow(ctx, newRenameParams)
+	if err != nil {
+		return fmt.Errorf("error while renaming feed - %w\n", err)
+	}
+
+	if *reset {
+		fmt.Printf("Feed \"%s\" is shown under its own name again!\n", feed.Name)
+	} else {
+		fmt.Printf("Feed \"%s\" is now shown to you as \"%s\"!\n", feed.Name, displayName.String)
+	}
+	return nil
+}
+
+// getFollow finds current user's follow of feed with given URL
+func getFollow(ctx context.Context, s *config.State, user database.User, feedURL string) (database.FeedFollow, database.Feed, error) {
+	feed, err := getFeedByURL(ctx, s, feedURL)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return database.FeedFollow{}, database.Feed{}, fmt.Errorf("given feed does not exist in the database\n")
+		}
+		return database.FeedFollow{}, database.Feed{}, fmt.Errorf("error while getting feed from the database - %w\n", err)
+	}
+
+	follow, err := s.Db.GetFeedFollow(ctx, database.GetFeedFollowParams{
+		UserID: user.ID,
+		FeedID: feed.ID,
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return database.FeedFollow{}, database.Feed{}, fmt.Errorf("you don't follow \"%s\"\n", feed.Name)
+		}
+		return database.FeedFollow{}, database.Feed{}, fmt.Errorf("error while getting follow from the database - %w\n", err)
+	}
+
+	return follow, feed, nil
+}
+
+// normalizeTag lowercases tag, tags can't contain whitespace or commas as they are listed comma separated
+func normalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" || strings.ContainsAny(tag, ", \t\n") {
+		return "", fmt.Errorf("incorrect tag \"%s\", tags can't be empty or contain whitespace or commas\n", tag)
+	}
+
+	return tag, nil
+}
+
+// tagArg turns optional --tag flag value into query parameter
+func tagArg(tag string) (sql.NullString, error) {
+	if tag == "" {
+		return sql.NullString{}, nil
+	}
+
+	normalized, err := normalizeTag(tag)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{
+		String: normalized,
+		Valid: true,
+	}, nil
+}
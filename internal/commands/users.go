@@ -0,0 +1,272 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+	"github.com/MedrekIT/gator/internal/config"
+	"github.com/MedrekIT/gator/internal/database"
+)
+
+// cmdDeleteUser removes user together with everything that belongs to them, the database is backed up first,
+// feeds are shared, so ones the user added are handed over to their oldest other follower or are left without owner
+func cmdDeleteUser(s *config.State, cmd Command) error {
+	usage := "deluser [--yes] <user_name>"
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	yes := fs.Bool("yes", false, "")
+	args, err := parseFlags(fs, cmd.Args, usage)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("Incorrect usage\nTry '%s'\n", usage)
+	}
+
+	ctx := context.Background()
+	user, err := getUser(ctx, s, args[0])
+	if err != nil {
+		return err
+	}
+
+	if !*yes {
+		ok, err := confirm(fmt.Sprintf("Remove user \"%s\" together with their follows, read and starred posts and filters?", user.Name))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Printf("User \"%s\" has been kept!\n", user.Name)
+			return nil
+		}
+	}
+
+	if err := backupDb(ctx, s, "deluser"); err != nil {
+		return err
+	}
+	owner := sql.NullString{
+		String: user.ID,
+		Valid: true,
+	}
+	var feeds, handedOver int64
+	err = s.Db.InTx(ctx, func(db *database.Store) error {
+		feeds, err = db.CountFeedsOfUser(ctx, owner)
+		if err != nil {
+			return fmt.Errorf("error while counting feeds of the user - %w\n", err)
+		}
+		handedOver, err = db.TransferFeedsOfUser(ctx, database.TransferFeedsOfUserParams{
+			UserID: owner,
+			UpdatedAt: time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("error while handing over feeds of the user - %w\n", err)
+		}
+		// feeds nobody else follows lose their owner together with the user
+		err = db.DeleteUser(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("error while removing user from the database - %w\n", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if s.Conf.CurrentUserName == user.Name {
+		if err := s.Conf.SetUser(""); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("User \"%s\" has been removed, %d feeds they added were handed over to other followers and %d were left without owner!\n", user.Name, handedOver, feeds-handedOver)
+	return nil
+}
+
+func cmdRenameUser(s *config.State, cmd Command) error {
+	if len(cmd.Args) != 2 {
+		return fmt.Errorf("Incorrect usage\nTry 'renameuser <user_name> <new_user_name>'\n")
+	}
+	newName := strings.TrimSpace(cmd.Args[1])
+	if newName == "" {
+		return fmt.Errorf("user name can't be empty\n")
+	}
+
+	ctx := context.Background()
+	user, err := getUser(ctx, s, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+
+	newRenameParams := database.RenameUserParams{
+		ID: user.ID,
+		Name: newName,
+		UpdatedAt: time.Now(),
+	}
+	renamed, err := s.Db.RenameUser(ctx, newRenameParams)
+	if err != nil {
+		if errors.Is(err, database.ErrDuplicateUser) {
+			return fmt.Errorf("user with given name already exists in the database\n")
+		}
+		return fmt.Errorf("error while renaming user - %w\n", err)
+	}
+
+	// logged in user stays logged in under the new name
+	if s.Conf.CurrentUserName == user.Name {
+		if err := s.Conf.SetUser(renamed.Name); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("User \"%s\" has been renamed to \"%s\"!\n", user.Name, renamed.Name)
+	return nil
+}
+
+// cmdReset removes all posts, personal data of a single user or everything, always backing the database up first
+func cmdReset(s *config.State, cmd Command) error {
+	usage := "reset [--yes] --posts | --user <user_name> | --all"
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	yes := fs.Bool("yes", false, "")
+	posts := fs.Bool("posts", false, "")
+	userName := fs.String("user", "", "")
+	all := fs.Bool("all", false, "")
+	args, err := parseFlags(fs, cmd.Args, usage)
+	if err != nil {
+		return err
+	}
+	scopes := 0
+	for _, set := range []bool{*posts, *userName != "", *all} {
+		if set {
+			scopes++
+		}
+	}
+	if len(args) != 0 || scopes != 1 {
+		return fmt.Errorf("Incorrect usage, exactly one of '--posts', '--user', '--all' has to be given\nTry '%s'\n", usage)
+	}
+
+	ctx := context.Background()
+	var user database.User
+	question := "Remove every user, feed, post and filter?"
+	operation := "reset"
+	switch {
+	case *posts:
+		question = "Remove every post, together with read and starred marks of all users?"
+		operation = "reset-posts"
+	case *userName != "":
+		user, err = getUser(ctx, s, *userName)
+		if err != nil {
+			return err
+		}
+		question = fmt.Sprintf("Remove follows, tags, read and starred posts and filters of user \"%s\"?", user.Name)
+		operation = "reset-user"
+	}
+
+	if !*yes {
+		ok, err := confirm(question)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Printf("Nothing has been reset!\n")
+			return nil
+		}
+	}
+	if err := backupDb(ctx, s, operation); err != nil {
+		return err
+	}
+
+	switch {
+	case *posts:
+		var removed int64
+		err := s.Db.InTx(ctx, func(db *database.Store) error {
+			var err error
+			removed, err = db.DeleteAllPosts(ctx)
+			if err != nil {
+				return fmt.Errorf("error while removing posts from the database - %w\n", err)
+			}
+			// posts are fetched anew, including the pruned ones
+			err = db.DeletePrunedPosts(ctx)
+			if err != nil {
+				return fmt.Errorf("error while removing posts from the database - %w\n", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d posts have been removed!\n", removed)
+	case *userName != "":
+		return resetUser(ctx, s, user)
+	default:
+		err := s.Db.InTx(ctx, func(db *database.Store) error {
+			err := db.ResetDb(ctx)
+			if err != nil {
+				return fmt.Errorf("error while resetting database - %w\n", err)
+			}
+			// feeds outlive their owners, so they have to be removed on their own
+			err = db.DeleteAllFeeds(ctx)
+			if err != nil {
+				return fmt.Errorf("error while resetting database - %w\n", err)
+			}
+			err = db.DeletePrunedPosts(ctx)
+			if err != nil {
+				return fmt.Errorf("error while resetting database - %w\n", err)
+			}
+			// global filters have no owner either
+			err = db.DeleteGlobalFilters(ctx)
+			if err != nil {
+				return fmt.Errorf("error while resetting database - %w\n", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Database has been reset!\n")
+	}
+	return nil
+}
+
+// resetUser leaves user with a clean slate in a single transaction, feeds they added stay saved for their other followers
+func resetUser(ctx context.Context, s *config.State, user database.User) error {
+	var follows, reads, stars, filters int64
+	err := s.Db.InTx(ctx, func(db *database.Store) error {
+		var err error
+		follows, err = db.DeleteFeedFollowsForUser(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("error while removing follows of the user - %w\n", err)
+		}
+		reads, err = db.DeletePostReadsForUser(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("error while removing read posts of the user - %w\n", err)
+		}
+		stars, err = db.DeletePostStarsForUser(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("error while removing starred posts of the user - %w\n", err)
+		}
+		filters, err = db.DeleteFiltersForUser(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("error while removing filters of the user - %w\n", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("User \"%s\" has been reset, %d follows, %d read marks, %d stars and %d filters were removed!\n", user.Name, follows, reads, stars, filters)
+	return nil
+}
+
+func getUser(ctx context.Context, s *config.State, name string) (database.User, error) {
+	user, err := s.Db.GetUser(ctx, name)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return database.User{}, fmt.Errorf("user with given name does not exist in the database\n")
+		}
+		return database.User{}, fmt.Errorf("error while getting user from the database - %w\n", err)
+	}
+
+	return user, nil
+}
@@ -3,8 +3,12 @@ package config
 import (
 	"fmt"
 	"os"
+	"database/sql"
 	"encoding/json"
+	"github.com/MedrekIT/gator/internal/backuping"
+	"github.com/MedrekIT/gator/internal/connecting"
 	"github.com/MedrekIT/gator/internal/database"
+	"github.com/MedrekIT/gator/internal/rewriting"
 )
 
 const configPath = "/.local/share/gator"
@@ -13,13 +17,17 @@ const dbPath = "/.local/share/gator/db/"
 const dbFile = "gator.db"
 
 type State struct {
-	Db *database.Queries
+	Db *database.Store
+	DbConn *sql.DB
 	Conf *Config
 }
 
 type Config struct {
 	DbPath string `json:"db_path"`
 	CurrentUserName string `json:"current_user_name"`
+	Rewrite rewriting.Config `json:"rewrite,omitempty"`
+	Backup backuping.Config `json:"backup,omitempty"`
+	Database connecting.Config `json:"database,omitempty"`
 }
 
 func (c *Config) SetUser(userName string) error {
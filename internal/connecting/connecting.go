@@ -0,0 +1,99 @@
+package connecting
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"time"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const (
+	DefaultJournalMode = "WAL"
+	DefaultSynchronous = "NORMAL"
+	DefaultBusyTimeout = 5 * time.Second
+	DefaultMaxOpenConns = 4
+)
+
+// Mode decides what connections opened to the database are allowed to do
+type Mode int
+
+const (
+	ReadWrite Mode = iota
+	// ReadOnly connections are used by commands which only display data, they never take the write lock
+	ReadOnly
+	// Schema connections are used for migrations, which rebuild tables, so foreign keys are off to keep them from cascading,
+	// a single connection is used, as goose runs its statements on any connection of the pool
+	Schema
+)
+
+// Config is kept in the config file, it tunes connections to the database, every unset value falls back to its default,
+// WAL journal with a busy timeout lets 'agg' write while other commands read the database
+type Config struct {
+	JournalMode string `json:"journal_mode,omitempty"`
+	Synchronous string `json:"synchronous,omitempty"`
+	BusyTimeout string `json:"busy_timeout,omitempty"`
+	MaxOpenConns int `json:"max_open_conns,omitempty"`
+	MaxIdleConns int `json:"max_idle_conns,omitempty"`
+}
+
+// Open opens the database at given path, every connection of the pool is set up the same way through the DSN,
+// so pragmas such as foreign keys don't apply to a single pooled connection only
+func Open(path string, conf Config, mode Mode) (*sql.DB, error) {
+	busyTimeout := DefaultBusyTimeout
+	if conf.BusyTimeout != "" {
+		var err error
+		busyTimeout, err = time.ParseDuration(conf.BusyTimeout)
+		if err != nil || busyTimeout < 0 {
+			return nil, fmt.Errorf("incorrect database busy timeout \"%s\" in the config file\nTry [1s, 5s, 30s, ...]\n", conf.BusyTimeout)
+		}
+	}
+	if conf.MaxOpenConns < 0 || conf.MaxIdleConns < 0 {
+		return nil, fmt.Errorf("database connection limits in the config file can't be negative\n")
+	}
+
+	params := url.Values{}
+	params.Set("_journal_mode", valueOr(conf.JournalMode, DefaultJournalMode))
+	params.Set("_synchronous", valueOr(conf.Synchronous, DefaultSynchronous))
+	params.Set("_busy_timeout", fmt.Sprint(busyTimeout.Milliseconds()))
+	switch mode {
+	case ReadOnly:
+		params.Set("_foreign_keys", "1")
+		params.Set("_query_only", "1")
+	case Schema:
+		params.Set("_foreign_keys", "0")
+	default:
+		params.Set("_foreign_keys", "1")
+		// transactions take the write lock at once, so they wait for the busy timeout instead of failing when upgrading a read
+		params.Set("_txlock", "immediate")
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+path+"?"+params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("error while opening the database - %w\n", err)
+	}
+
+	maxOpenConns := DefaultMaxOpenConns
+	if conf.MaxOpenConns != 0 {
+		maxOpenConns = conf.MaxOpenConns
+	}
+	maxIdleConns := maxOpenConns
+	if conf.MaxIdleConns != 0 {
+		maxIdleConns = conf.MaxIdleConns
+	}
+	if mode == Schema {
+		maxOpenConns = 1
+		maxIdleConns = 1
+	}
+	db.SetMaxOpenConns(maxOpenConns)
+	db.SetMaxIdleConns(maxIdleConns)
+
+	return db, nil
+}
+
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,421 @@
+package database
+
+// Browsing is written by hand, its SQL is compiled from the query given by user, only with placeholders for values
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const (
+	SortPublished = "published"
+	SortFetched   = "fetched"
+)
+
+// BrowseFilter describes which posts to browse, values of the same kind are alternatives and different kinds must all match
+type BrowseFilter struct {
+	UserID     string
+	Feeds      []string
+	Tags       []string
+	Titles     []string
+	Authors    []string
+	Categories []string
+	Text       []string
+	After      sql.NullTime
+	Before     sql.NullTime
+	Read       sql.NullBool
+	Starred    bool
+	Sort       string
+	Ascending  bool
+	Cursor     *BrowseCursor
+	Limit      int64
+	Offset     int64
+}
+
+// BrowseCursor points at the last browsed post, the next page starts right after it
+type BrowseCursor struct {
+	SortKey string
+	ID      string
+}
+
+type BrowsePostsRow struct {
+	ID          string
+	Title       string
+	Url         string
+	Description sql.NullString
+	PublishedAt sql.NullTime
+	Authors     string
+	Categories  string
+	FeedNames   string
+	IsRead      bool
+	IsStarred   bool
+	SortKey     string
+}
+
+// plans of matching word terms with the full-text index, picked by how many posts they match,
+// so neither rare nor common words make browsing walk more than it has to
+const (
+	// few posts match, browsing starts from them
+	textDriven = iota + 1
+	// matches are collected once while posts are walked in order
+	textCollected
+	// most posts match, every walked post is checked on its own, which costs more per post but stops with the page
+	textChecked
+)
+
+// limits of matches up to which a plan is used, around them neighbouring plans take about the same time with a million posts
+const (
+	textDrivenLimit    = 2000
+	textCollectedLimit = 50000
+)
+
+// textMatch is the full-text query of word terms and the plan it is matched with, without a plan terms are matched with LIKE
+type textMatch struct {
+	query string
+	plan  int
+}
+
+// sortKeys are normalized by datetime(), so posts from different time zones are ordered correctly,
+// both are indexed with the same expressions, so browsing walks posts in order and stops once the page is full
+var sortKeys = map[string]string{
+	SortPublished: "datetime(COALESCE(posts.published_at, posts.created_at))",
+	SortFetched:   "datetime(posts.created_at)",
+}
+
+// compileBrowse builds the query of posts for given filter, the same story from many feeds is returned once,
+// as the post seen first, joins are written as CROSS JOIN to keep SQLite from reordering them, every one starts from a single post
+func compileBrowse(f BrowseFilter, text textMatch) (string, []any, error) {
+	sortKey, ok := sortKeys[f.Sort]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown sort order \"%s\"", f.Sort)
+	}
+
+	var args []any
+	var postConds []string
+
+	storyConds, storyArgs := followConds(f)
+	// sources of a post which are followed by the user and match feed filters
+	sources := `CROSS JOIN post_sources
+	ON post_sources.post_id = story_posts.id
+	CROSS JOIN feed_follows
+	ON feed_follows.feed_id = post_sources.feed_id
+	CROSS JOIN feeds
+	ON feeds.id = post_sources.feed_id
+	WHERE ` + strings.Join(storyConds, "\n\tAND ")
+
+	query := `SELECT posts.id, posts.title, posts.url, posts.description, posts.published_at, posts.authors,
+	(SELECT json_group_array(post_categories.category)
+	FROM post_categories
+	WHERE post_categories.post_id = posts.id) AS categories,
+	(SELECT json_group_array(DISTINCT COALESCE(feed_follows.display_name, feeds.name))
+	FROM posts AS story_posts
+	` + sources + `
+	AND story_posts.story_id = posts.story_id) AS feed_names,
+	EXISTS (
+		SELECT 1
+		FROM posts AS read_posts
+		CROSS JOIN post_reads
+		ON post_reads.post_id = read_posts.id
+		WHERE read_posts.story_id = posts.story_id
+		AND post_reads.user_id = ?
+	) AS is_read,
+	EXISTS (
+		SELECT 1
+		FROM posts AS starred_posts
+		CROSS JOIN post_stars
+		ON post_stars.post_id = starred_posts.id
+		WHERE starred_posts.story_id = posts.story_id
+		AND post_stars.user_id = ?
+	) AS is_starred,
+	` + sortKey + ` AS sort_key
+`
+	args = append(args, storyArgs...)
+	args = append(args, f.UserID, f.UserID)
+	// starred posts are few, so they are found through stars of the user instead of walking every post in order
+	if f.Starred {
+		query += `FROM (
+	SELECT DISTINCT starred_posts.story_id
+	FROM post_stars
+	CROSS JOIN posts AS starred_posts
+	ON starred_posts.id = post_stars.post_id
+	WHERE post_stars.user_id = ?
+) AS starred_stories
+CROSS JOIN posts
+ON posts.story_id = starred_stories.story_id`
+		args = append(args, f.UserID)
+	} else if text.plan == textDriven {
+		query += `FROM (
+	SELECT rowid AS post_rowid
+	FROM posts_fts
+	WHERE posts_fts MATCH ?
+) AS matches
+CROSS JOIN posts
+ON posts.rowid = matches.post_rowid`
+		args = append(args, text.query)
+	} else {
+		query += "FROM posts"
+	}
+
+	postConds = append(postConds, `EXISTS (
+	SELECT 1
+	FROM posts AS story_posts
+	`+sources+`
+	AND story_posts.id = posts.id
+)`)
+	args = append(args, storyArgs...)
+	// posts of the story seen earlier are the ones shown
+	postConds = append(postConds, `NOT EXISTS (
+	SELECT 1
+	FROM posts AS story_posts
+	`+sources+`
+	AND story_posts.story_id = posts.story_id
+	AND (story_posts.created_at < posts.created_at
+	OR story_posts.created_at = posts.created_at AND story_posts.id < posts.id)
+)`)
+	args = append(args, storyArgs...)
+
+	if len(f.Titles) != 0 {
+		postConds = append(postConds, anyOf("posts.title LIKE '%' || ? || '%'", len(f.Titles)))
+		args = appendAll(args, f.Titles)
+	}
+	if len(f.Authors) != 0 {
+		postConds = append(postConds, anyOf("posts.authors LIKE '%' || ? || '%'", len(f.Authors)))
+		args = appendAll(args, f.Authors)
+	}
+	if len(f.Categories) != 0 {
+		postConds = append(postConds, `EXISTS (
+	SELECT 1
+	FROM post_categories
+	WHERE post_categories.post_id = posts.id
+	AND post_categories.category COLLATE NOCASE IN (`+strings.TrimSuffix(strings.Repeat("?, ", len(f.Categories)), ", ")+`)
+)`)
+		args = appendAll(args, f.Categories)
+	}
+	likeTerms := f.Text
+	if text.plan != 0 {
+		_, likeTerms = matchQuery(f.Text)
+	}
+	switch text.plan {
+	case textCollected:
+		// the unary plus keeps SQLite from starting with the matches, it walks posts in order instead
+		postConds = append(postConds, `+posts.rowid IN (
+	SELECT rowid
+	FROM posts_fts
+	WHERE posts_fts MATCH ?
+)`)
+		args = append(args, text.query)
+	case textChecked:
+		postConds = append(postConds, `EXISTS (
+	SELECT 1
+	FROM posts_fts
+	WHERE posts_fts MATCH ?
+	AND posts_fts.rowid = posts.rowid
+)`)
+		args = append(args, text.query)
+	}
+	for _, term := range likeTerms {
+		postConds = append(postConds, "(posts.title LIKE '%' || ? || '%' OR posts.description LIKE '%' || ? || '%')")
+		args = append(args, term, term)
+	}
+	if f.After.Valid {
+		postConds = append(postConds, "datetime(COALESCE(posts.published_at, posts.created_at)) >= datetime(?)")
+		args = append(args, f.After.Time)
+	}
+	if f.Before.Valid {
+		postConds = append(postConds, "datetime(COALESCE(posts.published_at, posts.created_at)) < datetime(?)")
+		args = append(args, f.Before.Time)
+	}
+	if f.Read.Valid {
+		postConds = append(postConds, "is_read = ?")
+		args = append(args, f.Read.Bool)
+	}
+	if f.Starred {
+		postConds = append(postConds, "is_starred")
+	}
+
+	order := "DESC"
+	if f.Ascending {
+		order = "ASC"
+	}
+	if f.Cursor != nil {
+		op := "<"
+		if f.Ascending {
+			op = ">"
+		}
+		postConds = append(postConds, "("+sortKey+", posts.id) "+op+" (?, ?)")
+		args = append(args, f.Cursor.SortKey, f.Cursor.ID)
+	}
+
+	if len(postConds) != 0 {
+		query += "\nWHERE " + strings.Join(postConds, "\nAND ")
+	}
+	query += "\nORDER BY sort_key " + order + ", posts.id " + order + "\nLIMIT ?\nOFFSET ?"
+	args = append(args, f.Limit, f.Offset)
+
+	return query, args, nil
+}
+
+// followConds matches follows of the user which posts have to come from, a post is browsed when any of its sources matches
+func followConds(f BrowseFilter) ([]string, []any) {
+	conds := []string{"feed_follows.user_id = ?"}
+	args := []any{f.UserID}
+	if len(f.Feeds) != 0 {
+		conds = append(conds, anyOf("COALESCE(feed_follows.display_name, feeds.name) LIKE '%' || ? || '%'", len(f.Feeds)))
+		args = appendAll(args, f.Feeds)
+	}
+	if len(f.Tags) != 0 {
+		conds = append(conds, `EXISTS (
+		SELECT 1
+		FROM follow_tags
+		WHERE follow_tags.follow_id = feed_follows.id
+		AND follow_tags.tag IN (`+strings.TrimSuffix(strings.Repeat("?, ", len(f.Tags)), ", ")+`)
+	)`)
+		args = appendAll(args, f.Tags)
+	}
+
+	return conds, args
+}
+
+// hasMatchingFollows checks feed and tag filters against follows alone, when none of them match,
+// browsing would otherwise walk every post only to find nothing
+func (q *Queries) hasMatchingFollows(ctx context.Context, f BrowseFilter) (bool, error) {
+	conds, args := followConds(f)
+	query := `SELECT EXISTS (
+	SELECT 1
+	FROM feed_follows
+	CROSS JOIN feeds
+	ON feeds.id = feed_follows.feed_id
+	WHERE ` + strings.Join(conds, "\n\tAND ") + `
+)`
+
+	var matching bool
+	err := q.db.QueryRowContext(ctx, query, args...).Scan(&matching)
+	return matching, err
+}
+
+// matchQuery builds full-text query of terms holding words, every one is a phrase matched in titles and descriptions,
+// terms without letters or digits have no words to look up, they are returned to be matched with LIKE
+func matchQuery(terms []string) (string, []string) {
+	var phrases, rest []string
+	for _, term := range terms {
+		if !strings.ContainsFunc(term, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsNumber(r) }) {
+			rest = append(rest, term)
+			continue
+		}
+		phrases = append(phrases, `{title description} : "`+strings.ReplaceAll(term, `"`, `""`)+`"`)
+	}
+
+	return strings.Join(phrases, " AND "), rest
+}
+
+// planText picks how word terms are matched, with the full-text index when this build and the database have it,
+// counting matches only up to the point where the plan can't change anymore
+func (q *Queries) planText(ctx context.Context, f BrowseFilter) (textMatch, error) {
+	if len(f.Text) == 0 {
+		return textMatch{}, nil
+	}
+	query, _ := matchQuery(f.Text)
+	if query == "" {
+		return textMatch{}, nil
+	}
+	available, err := q.SearchAvailable(ctx)
+	if err != nil || !available {
+		return textMatch{}, err
+	}
+	// starred posts are few, checking each of them is cheaper than looking up every match
+	if f.Starred {
+		return textMatch{query: query, plan: textChecked}, nil
+	}
+
+	var matches int
+	err = q.db.QueryRowContext(ctx, `SELECT COUNT(*)
+FROM (
+	SELECT 1
+	FROM posts_fts
+	WHERE posts_fts MATCH ?
+	LIMIT ?
+)`, query, textCollectedLimit+1).Scan(&matches)
+	if err != nil {
+		return textMatch{}, err
+	}
+
+	switch {
+	case matches <= textDrivenLimit:
+		return textMatch{query: query, plan: textDriven}, nil
+	case matches <= textCollectedLimit:
+		return textMatch{query: query, plan: textCollected}, nil
+	default:
+		return textMatch{query: query, plan: textChecked}, nil
+	}
+}
+
+func (q *Queries) BrowsePosts(ctx context.Context, f BrowseFilter) ([]BrowsePostsRow, error) {
+	if len(f.Feeds) != 0 || len(f.Tags) != 0 {
+		matching, err := q.hasMatchingFollows(ctx, f)
+		if err != nil || !matching {
+			return nil, err
+		}
+	}
+	text, err := q.planText(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	query, args, err := compileBrowse(f, text)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BrowsePostsRow
+	for rows.Next() {
+		var i BrowsePostsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.Authors,
+			&i.Categories,
+			&i.FeedNames,
+			&i.IsRead,
+			&i.IsStarred,
+			&i.SortKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// anyOf joins n copies of condition with OR
+func anyOf(cond string, n int) string {
+	conds := make([]string, n)
+	for i := range conds {
+		conds[i] = cond
+	}
+
+	return "(" + strings.Join(conds, " OR ") + ")"
+}
+
+func appendAll(args []any, values []string) []any {
+	for _, v := range values {
+		args = append(args, v)
+	}
+
+	return args
+}
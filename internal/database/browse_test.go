@@ -0,0 +1,235 @@
+package database_test
+
+// Benchmarks run against a database seeded with 1M posts, the size at which browsing and unread counts used to take seconds,
+// seeding takes a while, so it's done once and only when benchmarks are run: go test -tags sqlite_fts5 -run '^$' -bench . ./internal/database
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MedrekIT/gator/internal/connecting"
+	"github.com/MedrekIT/gator/internal/database"
+	embedding "github.com/MedrekIT/gator/sql"
+)
+
+const (
+	benchUser  = "user-bench"
+	benchFeeds = 500
+	// the user follows every fifth feed, with posts spread evenly that's a fifth of all posts
+	benchFollowEvery = 5
+	benchPosts       = 1000000
+	benchReads       = 20000
+	benchStars       = 500
+	// commands have to answer within the target even with this many posts
+	benchTarget = 50 * time.Millisecond
+)
+
+var (
+	benchOnce sync.Once
+	benchDir  string
+	benchErr  error
+)
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if benchDir != "" {
+		os.RemoveAll(benchDir)
+	}
+	os.Exit(code)
+}
+
+// benchStore opens the seeded database the way read-only commands do
+func benchStore(b *testing.B) *database.Store {
+	b.Helper()
+	benchOnce.Do(func() {
+		benchDir, benchErr = os.MkdirTemp("", "gator-bench")
+		if benchErr == nil {
+			benchErr = seedBench(filepath.Join(benchDir, "gator.db"))
+		}
+	})
+	if benchErr != nil {
+		b.Fatal(benchErr)
+	}
+
+	db, err := connecting.Open(filepath.Join(benchDir, "gator.db"), connecting.Config{}, connecting.ReadOnly)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { db.Close() })
+	return database.NewStore(db)
+}
+
+// seedBench migrates a fresh database and fills it with generated feeds and posts, published over the last three years,
+// every followed feed gets a tag, a few posts share categories, and some of the oldest followed posts are read or starred
+func seedBench(path string) error {
+	db, err := connecting.Open(path, connecting.Config{}, connecting.Schema)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	if err := embedding.DbEmbedding(db); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	seed := []string{
+		`INSERT INTO users (id, created_at, updated_at, name)
+		VALUES ('` + benchUser + `', '2026-10-01 00:00:00', '2026-10-01 00:00:00', 'bench')`,
+		`WITH RECURSIVE n(i) AS (SELECT 0 UNION ALL SELECT i + 1 FROM n WHERE i < @feeds - 1)
+		INSERT INTO feeds (id, created_at, updated_at, name, url, user_id)
+		SELECT printf('feed-%03d', i), '2026-10-01 00:00:00', '2026-10-01 00:00:00', printf('Feed %d', i), printf('http://bench/%d.xml', i), '` + benchUser + `'
+		FROM n`,
+		`INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id)
+		SELECT 'follow-' || id, created_at, updated_at, user_id, id
+		FROM feeds
+		WHERE CAST(substr(id, 6) AS INTEGER) % @follow_every = 0`,
+		`INSERT INTO follow_tags (follow_id, tag, created_at)
+		SELECT id, CASE WHEN CAST(substr(feed_id, 6) AS INTEGER) % 2 = 0 THEN 'news' ELSE 'tech' END, created_at
+		FROM feed_follows`,
+		`WITH RECURSIVE n(i) AS (SELECT 0 UNION ALL SELECT i + 1 FROM n WHERE i < @posts - 1),
+		generated AS (
+			SELECT i, printf('post-%07d', i) AS id, printf('feed-%03d', i % @feeds) AS feed_id,
+				datetime('2026-10-01 00:00:00', printf('-%d minutes', (i * 7919) % 1576800)) AS published_at,
+				(i * 31) % 120 AS delay
+			FROM n
+		)
+		INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, story_id, authors)
+		SELECT id, datetime(published_at, printf('+%d minutes', delay)), datetime(published_at, printf('+%d minutes', delay)),
+			printf('Post %d', i), printf('http://bench/p/%d', i),
+			printf('Description of post %d', i) || CASE WHEN i % 40 = 0 THEN ' weekly' ELSE '' END || CASE WHEN i % 125 = 0 THEN ' monthly' ELSE '' END,
+			published_at, feed_id, id, ''
+		FROM generated`,
+		`INSERT INTO post_sources (post_id, feed_id, created_at)
+		SELECT id, feed_id, created_at
+		FROM posts`,
+		`INSERT INTO post_categories (post_id, category)
+		SELECT id, CASE WHEN CAST(substr(id, 6) AS INTEGER) % 10 = 0 THEN 'Go' ELSE 'Misc' END
+		FROM posts
+		WHERE CAST(substr(id, 6) AS INTEGER) % 5 = 0`,
+		`INSERT INTO post_reads (user_id, post_id, read_at)
+		SELECT '` + benchUser + `', posts.id, '2026-10-01 00:00:00'
+		FROM posts
+		INNER JOIN feed_follows
+		ON feed_follows.feed_id = posts.feed_id
+		ORDER BY posts.id
+		LIMIT @reads`,
+		`INSERT INTO post_stars (user_id, post_id, starred_at)
+		SELECT user_id, post_id, read_at
+		FROM post_reads
+		ORDER BY post_id
+		LIMIT @stars`,
+	}
+	for _, stmt := range seed {
+		if _, err := tx.Exec(stmt, namedArgs(stmt)...); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// namedArgs picks sizes of seeded data used by given statement, as SQLite refuses arguments a statement doesn't take
+func namedArgs(stmt string) []any {
+	sizes := map[string]int{
+		"feeds":        benchFeeds,
+		"follow_every": benchFollowEvery,
+		"posts":        benchPosts,
+		"reads":        benchReads,
+		"stars":        benchStars,
+	}
+	var args []any
+	for name, size := range sizes {
+		if strings.Contains(stmt, "@"+name) {
+			args = append(args, sql.Named(name, size))
+		}
+	}
+	return args
+}
+
+// checkTarget fails the benchmark when a single operation took longer than benchTarget on average
+func checkTarget(b *testing.B) {
+	b.Helper()
+	if perOp := b.Elapsed() / time.Duration(b.N); perOp > benchTarget {
+		b.Fatalf("%s per operation, the target is %s", perOp, benchTarget)
+	}
+}
+
+func BenchmarkBrowse(b *testing.B) {
+	store := benchStore(b)
+	unread := sql.NullBool{Bool: false, Valid: true}
+
+	cases := []struct {
+		name   string
+		filter database.BrowseFilter
+	}{
+		{"unread", database.BrowseFilter{Read: unread}},
+		{"all", database.BrowseFilter{}},
+		{"fetched", database.BrowseFilter{Read: unread, Sort: database.SortFetched}},
+		{"starred", database.BrowseFilter{Starred: true}},
+		{"read", database.BrowseFilter{Read: sql.NullBool{Bool: true, Valid: true}}},
+		{"feed", database.BrowseFilter{Read: unread, Feeds: []string{"Feed 15"}}},
+		{"feed_nonexistent", database.BrowseFilter{Read: unread, Feeds: []string{"Nonexistent"}}},
+		{"tag", database.BrowseFilter{Read: unread, Tags: []string{"news"}}},
+		{"tag_nonexistent", database.BrowseFilter{Read: unread, Tags: []string{"x"}}},
+		{"category", database.BrowseFilter{Read: unread, Categories: []string{"go"}}},
+		// words matched by a single post, by 0.8% and 2.5% of posts and by every post, which are matched with every plan of text terms
+		{"text", database.BrowseFilter{Read: unread, Text: []string{"post 99999"}}},
+		{"text_monthly", database.BrowseFilter{Read: unread, Text: []string{"monthly"}}},
+		{"text_weekly", database.BrowseFilter{Read: unread, Text: []string{"weekly"}}},
+		{"text_common", database.BrowseFilter{Read: unread, Text: []string{"description"}}},
+		{"text_starred", database.BrowseFilter{Starred: true, Text: []string{"post"}}},
+	}
+	for _, c := range cases {
+		b.Run(c.name, func(b *testing.B) {
+			f := c.filter
+			f.UserID = benchUser
+			if f.Sort == "" {
+				f.Sort = database.SortPublished
+			}
+			f.Limit = 20
+			for b.Loop() {
+				if _, err := store.BrowsePosts(context.Background(), f); err != nil {
+					b.Fatal(err)
+				}
+			}
+			checkTarget(b)
+		})
+	}
+}
+
+func BenchmarkFollowing(b *testing.B) {
+	store := benchStore(b)
+
+	cases := []struct {
+		name string
+		tag  sql.NullString
+	}{
+		{"all", sql.NullString{}},
+		{"tag", sql.NullString{String: "news", Valid: true}},
+	}
+	for _, c := range cases {
+		b.Run(c.name, func(b *testing.B) {
+			arg := database.GetFeedFollowsForUserParams{
+				UserID: benchUser,
+				Tag:    c.tag,
+			}
+			for b.Loop() {
+				if _, err := store.GetFeedFollowsForUser(context.Background(), arg); err != nil {
+					b.Fatal(err)
+				}
+			}
+			checkTarget(b)
+		})
+	}
+}
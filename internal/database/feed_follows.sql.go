@@ -7,9 +7,27 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 )
 
+const countOtherFollowers = `-- name: CountOtherFollowers :one
+SELECT COUNT(*) FROM feed_follows
+WHERE feed_id = ?1 AND user_id != ?2
+`
+
+type CountOtherFollowersParams struct {
+	FeedID string
+	UserID string
+}
+
+func (q *Queries) CountOtherFollowers(ctx context.Context, arg CountOtherFollowersParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countOtherFollowers, arg.FeedID, arg.UserID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createFeedFollow = `-- name: CreateFeedFollow :one
 INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id)
 VALUES (
@@ -26,7 +44,7 @@ WHERE feed_follows.user_id = users.id),
 (SELECT feeds.name AS feed_name
 FROM feeds
 WHERE feed_follows.feed_id = feeds.id),
-id, created_at, updated_at, user_id, feed_id
+id, created_at, updated_at, user_id, feed_id, display_name, unread_stories
 `
 
 type CreateFeedFollowParams struct {
@@ -38,13 +56,15 @@ type CreateFeedFollowParams struct {
 }
 
 type CreateFeedFollowRow struct {
-	UserName  string
-	FeedName  string
-	ID        string
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	UserID    string
-	FeedID    string
+	UserName      string
+	FeedName      string
+	ID            string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	UserID        string
+	FeedID        string
+	DisplayName   sql.NullString
+	UnreadStories int64
 }
 
 func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowParams) (CreateFeedFollowRow, error) {
@@ -64,6 +84,8 @@ func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowPara
 		&i.UpdatedAt,
 		&i.UserID,
 		&i.FeedID,
+		&i.DisplayName,
+		&i.UnreadStories,
 	)
 	return i, err
 }
@@ -83,24 +105,114 @@ func (q *Queries) DeleteFeedFollow(ctx context.Context, arg DeleteFeedFollowPara
 	return err
 }
 
+const deleteFeedFollowsForFeed = `-- name: DeleteFeedFollowsForFeed :execrows
+DELETE FROM feed_follows
+WHERE feed_id = ?1
+`
+
+func (q *Queries) DeleteFeedFollowsForFeed(ctx context.Context, feedID string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteFeedFollowsForFeed, feedID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteFeedFollowsForUser = `-- name: DeleteFeedFollowsForUser :execrows
+DELETE FROM feed_follows
+WHERE user_id = ?1
+`
+
+func (q *Queries) DeleteFeedFollowsForUser(ctx context.Context, userID string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteFeedFollowsForUser, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteUnstarredFeedFollowsForFeed = `-- name: DeleteUnstarredFeedFollowsForFeed :execrows
+DELETE FROM feed_follows
+WHERE feed_id = ?1
+AND user_id NOT IN (
+	SELECT post_stars.user_id
+	FROM post_stars
+	INNER JOIN posts
+	ON posts.id = post_stars.post_id
+	WHERE posts.feed_id = ?1
+)
+`
+
+func (q *Queries) DeleteUnstarredFeedFollowsForFeed(ctx context.Context, feedID string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteUnstarredFeedFollowsForFeed, feedID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getFeedFollow = `-- name: GetFeedFollow :one
+SELECT id, created_at, updated_at, user_id, feed_id, display_name, unread_stories FROM feed_follows
+WHERE user_id = ?1 AND feed_id = ?2
+`
+
+type GetFeedFollowParams struct {
+	UserID string
+	FeedID string
+}
+
+func (q *Queries) GetFeedFollow(ctx context.Context, arg GetFeedFollowParams) (FeedFollow, error) {
+	row := q.db.QueryRowContext(ctx, getFeedFollow, arg.UserID, arg.FeedID)
+	var i FeedFollow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.FeedID,
+		&i.DisplayName,
+		&i.UnreadStories,
+	)
+	return i, err
+}
+
 const getFeedFollowsForUser = `-- name: GetFeedFollowsForUser :many
 SELECT users.name AS user_name,
-feeds.name AS feed_name
+feeds.id, feeds.created_at, feeds.updated_at, feeds.name, feeds.url, feeds.user_id, feeds.last_fetched_at, feeds.site_link, feeds.description, feeds.language, feeds.image_url, feeds.generator, feeds.last_build_date, feeds.archived_at, feeds.last_attempted_at,
+feed_follows.display_name,
+feed_follows.unread_stories AS unread_count,
+COALESCE((SELECT group_concat(follow_tags.tag)
+FROM follow_tags
+WHERE follow_tags.follow_id = feed_follows.id), '') AS tags
 FROM feed_follows
 INNER JOIN users
 ON feed_follows.user_id = users.id
 INNER JOIN feeds
 ON feed_follows.feed_id = feeds.id
 WHERE users.id = ?1
+AND (?2 IS NULL OR EXISTS (
+	SELECT 1
+	FROM follow_tags
+	WHERE follow_tags.follow_id = feed_follows.id
+	AND follow_tags.tag = ?2
+))
 `
 
+type GetFeedFollowsForUserParams struct {
+	UserID string
+	Tag    sql.NullString
+}
+
 type GetFeedFollowsForUserRow struct {
-	UserName string
-	FeedName string
+	UserName    string
+	Feed        Feed
+	DisplayName sql.NullString
+	UnreadCount int64
+	Tags        string
 }
 
-func (q *Queries) GetFeedFollowsForUser(ctx context.Context, id string) ([]GetFeedFollowsForUserRow, error) {
-	rows, err := q.db.QueryContext(ctx, getFeedFollowsForUser, id)
+func (q *Queries) GetFeedFollowsForUser(ctx context.Context, arg GetFeedFollowsForUserParams) ([]GetFeedFollowsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedFollowsForUser, arg.UserID, arg.Tag)
 	if err != nil {
 		return nil, err
 	}
@@ -108,7 +220,27 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, id string) ([]GetFe
 	var items []GetFeedFollowsForUserRow
 	for rows.Next() {
 		var i GetFeedFollowsForUserRow
-		if err := rows.Scan(&i.UserName, &i.FeedName); err != nil {
+		if err := rows.Scan(
+			&i.UserName,
+			&i.Feed.ID,
+			&i.Feed.CreatedAt,
+			&i.Feed.UpdatedAt,
+			&i.Feed.Name,
+			&i.Feed.Url,
+			&i.Feed.UserID,
+			&i.Feed.LastFetchedAt,
+			&i.Feed.SiteLink,
+			&i.Feed.Description,
+			&i.Feed.Language,
+			&i.Feed.ImageUrl,
+			&i.Feed.Generator,
+			&i.Feed.LastBuildDate,
+			&i.Feed.ArchivedAt,
+			&i.Feed.LastAttemptedAt,
+			&i.DisplayName,
+			&i.UnreadCount,
+			&i.Tags,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -121,3 +253,65 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, id string) ([]GetFe
 	}
 	return items, nil
 }
+
+const moveFeedFollows = `-- name: MoveFeedFollows :exec
+UPDATE OR IGNORE feed_follows
+SET feed_id = ?1
+WHERE feed_id = ?2
+`
+
+type MoveFeedFollowsParams struct {
+	ToFeedID   string
+	FromFeedID string
+}
+
+func (q *Queries) MoveFeedFollows(ctx context.Context, arg MoveFeedFollowsParams) error {
+	_, err := q.db.ExecContext(ctx, moveFeedFollows, arg.ToFeedID, arg.FromFeedID)
+	return err
+}
+
+const moveFollowDisplayNames = `-- name: MoveFollowDisplayNames :exec
+UPDATE feed_follows
+SET display_name = (
+	SELECT dup.display_name
+	FROM feed_follows AS dup
+	WHERE dup.user_id = feed_follows.user_id
+	AND dup.feed_id = ?1
+)
+WHERE feed_id = ?2
+AND display_name IS NULL
+AND EXISTS (
+	SELECT 1
+	FROM feed_follows AS dup
+	WHERE dup.user_id = feed_follows.user_id
+	AND dup.feed_id = ?1
+	AND dup.display_name IS NOT NULL
+)
+`
+
+type MoveFollowDisplayNamesParams struct {
+	FromFeedID string
+	ToFeedID   string
+}
+
+func (q *Queries) MoveFollowDisplayNames(ctx context.Context, arg MoveFollowDisplayNamesParams) error {
+	_, err := q.db.ExecContext(ctx, moveFollowDisplayNames, arg.FromFeedID, arg.ToFeedID)
+	return err
+}
+
+const renameFeedFollow = `-- name: RenameFeedFollow :exec
+UPDATE feed_follows
+SET display_name = ?2, updated_at = ?3
+WHERE id = ?1
+`
+
+type RenameFeedFollowParams struct {
+	ID          string
+	DisplayName sql.NullString
+	UpdatedAt   time.Time
+}
+
+func (q *Queries) RenameFeedFollow(ctx context.Context, arg RenameFeedFollowParams) error {
+	_, err := q.db.ExecContext(ctx, renameFeedFollow, arg.ID, arg.DisplayName, arg.UpdatedAt)
+	return err
+}
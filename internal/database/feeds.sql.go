@@ -7,9 +7,26 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 )
 
+const archiveFeed = `-- name: ArchiveFeed :exec
+UPDATE feeds
+SET archived_at = ?2, updated_at = ?2
+WHERE id = ?1
+`
+
+type ArchiveFeedParams struct {
+	ID         string
+	ArchivedAt sql.NullTime
+}
+
+func (q *Queries) ArchiveFeed(ctx context.Context, arg ArchiveFeedParams) error {
+	_, err := q.db.ExecContext(ctx, archiveFeed, arg.ID, arg.ArchivedAt)
+	return err
+}
+
 const createFeed = `-- name: CreateFeed :one
 INSERT INTO feeds (id, created_at, updated_at, name, url, user_id)
 VALUES (
@@ -20,7 +37,7 @@ VALUES (
 	?5,
 	?6
 )
-RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, site_link, description, language, image_url, generator, last_build_date, archived_at, last_attempted_at
 `
 
 type CreateFeedParams struct {
@@ -29,7 +46,7 @@ type CreateFeedParams struct {
 	UpdatedAt time.Time
 	Name      string
 	Url       string
-	UserID    string
+	UserID    sql.NullString
 }
 
 func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, error) {
@@ -50,12 +67,39 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.SiteLink,
+		&i.Description,
+		&i.Language,
+		&i.ImageUrl,
+		&i.Generator,
+		&i.LastBuildDate,
+		&i.ArchivedAt,
+		&i.LastAttemptedAt,
 	)
 	return i, err
 }
 
+const deleteAllFeeds = `-- name: DeleteAllFeeds :exec
+DELETE FROM feeds
+`
+
+func (q *Queries) DeleteAllFeeds(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteAllFeeds)
+	return err
+}
+
+const deleteFeed = `-- name: DeleteFeed :exec
+DELETE FROM feeds
+WHERE id = ?1
+`
+
+func (q *Queries) DeleteFeed(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteFeed, id)
+	return err
+}
+
 const getFeedByURL = `-- name: GetFeedByURL :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, site_link, description, language, image_url, generator, last_build_date, archived_at, last_attempted_at FROM feeds
 WHERE url = ?1
 `
 
@@ -70,12 +114,20 @@ func (q *Queries) GetFeedByURL(ctx context.Context, url string) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.SiteLink,
+		&i.Description,
+		&i.Language,
+		&i.ImageUrl,
+		&i.Generator,
+		&i.LastBuildDate,
+		&i.ArchivedAt,
+		&i.LastAttemptedAt,
 	)
 	return i, err
 }
 
 const getFeeds = `-- name: GetFeeds :many
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, site_link, description, language, image_url, generator, last_build_date, archived_at, last_attempted_at FROM feeds
 `
 
 func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
@@ -95,6 +147,14 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
 			&i.Url,
 			&i.UserID,
 			&i.LastFetchedAt,
+			&i.SiteLink,
+			&i.Description,
+			&i.Language,
+			&i.ImageUrl,
+			&i.Generator,
+			&i.LastBuildDate,
+			&i.ArchivedAt,
+			&i.LastAttemptedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -109,9 +169,169 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
 	return items, nil
 }
 
-const getNextFeedToFetch = `-- name: GetNextFeedToFetch :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
+const getFeedsDueForFetch = `-- name: GetFeedsDueForFetch :many
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, site_link, description, language, image_url, generator, last_build_date, archived_at, last_attempted_at FROM feeds
+WHERE archived_at IS NULL
+AND EXISTS (
+	SELECT 1
+	FROM feed_follows
+	WHERE feed_follows.feed_id = feeds.id
+)
+AND (last_fetched_at IS NULL
+OR datetime(last_fetched_at) < datetime(?1))
+ORDER BY last_fetched_at IS NOT NULL, last_fetched_at
+`
+
+func (q *Queries) GetFeedsDueForFetch(ctx context.Context, fetchedBefore interface{}) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedsDueForFetch, fetchedBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.SiteLink,
+			&i.Description,
+			&i.Language,
+			&i.ImageUrl,
+			&i.Generator,
+			&i.LastBuildDate,
+			&i.ArchivedAt,
+			&i.LastAttemptedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFeedsWithOwners = `-- name: GetFeedsWithOwners :many
+SELECT feeds.id, feeds.created_at, feeds.updated_at, feeds.name, feeds.url, feeds.user_id, feeds.last_fetched_at, feeds.site_link, feeds.description, feeds.language, feeds.image_url, feeds.generator, feeds.last_build_date, feeds.archived_at, feeds.last_attempted_at, users.name AS owner_name
+FROM feeds
+LEFT JOIN users
+ON users.id = feeds.user_id
+`
+
+type GetFeedsWithOwnersRow struct {
+	Feed      Feed
+	OwnerName sql.NullString
+}
+
+func (q *Queries) GetFeedsWithOwners(ctx context.Context) ([]GetFeedsWithOwnersRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedsWithOwners)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFeedsWithOwnersRow
+	for rows.Next() {
+		var i GetFeedsWithOwnersRow
+		if err := rows.Scan(
+			&i.Feed.ID,
+			&i.Feed.CreatedAt,
+			&i.Feed.UpdatedAt,
+			&i.Feed.Name,
+			&i.Feed.Url,
+			&i.Feed.UserID,
+			&i.Feed.LastFetchedAt,
+			&i.Feed.SiteLink,
+			&i.Feed.Description,
+			&i.Feed.Language,
+			&i.Feed.ImageUrl,
+			&i.Feed.Generator,
+			&i.Feed.LastBuildDate,
+			&i.Feed.ArchivedAt,
+			&i.Feed.LastAttemptedAt,
+			&i.OwnerName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFollowedFeeds = `-- name: GetFollowedFeeds :many
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, site_link, description, language, image_url, generator, last_build_date, archived_at, last_attempted_at FROM feeds
+WHERE archived_at IS NULL
+AND EXISTS (
+	SELECT 1
+	FROM feed_follows
+	WHERE feed_follows.feed_id = feeds.id
+)
 ORDER BY last_fetched_at IS NOT NULL, last_fetched_at
+`
+
+func (q *Queries) GetFollowedFeeds(ctx context.Context) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, getFollowedFeeds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.SiteLink,
+			&i.Description,
+			&i.Language,
+			&i.ImageUrl,
+			&i.Generator,
+			&i.LastBuildDate,
+			&i.ArchivedAt,
+			&i.LastAttemptedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getNextFeedToFetch = `-- name: GetNextFeedToFetch :one
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, site_link, description, language, image_url, generator, last_build_date, archived_at, last_attempted_at FROM feeds
+WHERE archived_at IS NULL
+AND EXISTS (
+	SELECT 1
+	FROM feed_follows
+	WHERE feed_follows.feed_id = feeds.id
+)
+ORDER BY COALESCE(last_attempted_at, last_fetched_at) IS NOT NULL, COALESCE(last_attempted_at, last_fetched_at)
 LIMIT 1
 `
 
@@ -126,15 +346,89 @@ func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.SiteLink,
+		&i.Description,
+		&i.Language,
+		&i.ImageUrl,
+		&i.Generator,
+		&i.LastBuildDate,
+		&i.ArchivedAt,
+		&i.LastAttemptedAt,
 	)
 	return i, err
 }
 
+const getOrphanedFeeds = `-- name: GetOrphanedFeeds :many
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, site_link, description, language, image_url, generator, last_build_date, archived_at, last_attempted_at FROM feeds
+WHERE NOT EXISTS (
+	SELECT 1
+	FROM feed_follows
+	WHERE feed_follows.feed_id = feeds.id
+)
+AND datetime(COALESCE(last_fetched_at, created_at)) < datetime(?1)
+ORDER BY COALESCE(last_fetched_at, created_at)
+`
+
+func (q *Queries) GetOrphanedFeeds(ctx context.Context, idleBefore interface{}) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, getOrphanedFeeds, idleBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.SiteLink,
+			&i.Description,
+			&i.Language,
+			&i.ImageUrl,
+			&i.Generator,
+			&i.LastBuildDate,
+			&i.ArchivedAt,
+			&i.LastAttemptedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markFeedAttempted = `-- name: MarkFeedAttempted :exec
+UPDATE feeds
+SET last_attempted_at = ?2
+WHERE id = ?1
+`
+
+type MarkFeedAttemptedParams struct {
+	ID              string
+	LastAttemptedAt sql.NullTime
+}
+
+func (q *Queries) MarkFeedAttempted(ctx context.Context, arg MarkFeedAttemptedParams) error {
+	_, err := q.db.ExecContext(ctx, markFeedAttempted, arg.ID, arg.LastAttemptedAt)
+	return err
+}
+
 const markFeedFetched = `-- name: MarkFeedFetched :one
 UPDATE feeds
-SET updated_at = ?2, last_fetched_at = ?2
+SET updated_at = ?2, last_fetched_at = ?2, last_attempted_at = ?2
 WHERE id = ?1
-RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, site_link, description, language, image_url, generator, last_build_date, archived_at, last_attempted_at
 `
 
 type MarkFeedFetchedParams struct {
@@ -153,6 +447,155 @@ func (q *Queries) MarkFeedFetched(ctx context.Context, arg MarkFeedFetchedParams
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.SiteLink,
+		&i.Description,
+		&i.Language,
+		&i.ImageUrl,
+		&i.Generator,
+		&i.LastBuildDate,
+		&i.ArchivedAt,
+		&i.LastAttemptedAt,
+	)
+	return i, err
+}
+
+const transferFeedsOfUser = `-- name: TransferFeedsOfUser :execrows
+UPDATE feeds
+SET user_id = (
+	SELECT feed_follows.user_id
+	FROM feed_follows
+	WHERE feed_follows.feed_id = feeds.id
+	AND feed_follows.user_id != ?1
+	ORDER BY feed_follows.created_at
+	LIMIT 1
+), updated_at = ?2
+WHERE user_id = ?1
+AND EXISTS (
+	SELECT 1
+	FROM feed_follows
+	WHERE feed_follows.feed_id = feeds.id
+	AND feed_follows.user_id != ?1
+)
+`
+
+type TransferFeedsOfUserParams struct {
+	UserID    sql.NullString
+	UpdatedAt time.Time
+}
+
+func (q *Queries) TransferFeedsOfUser(ctx context.Context, arg TransferFeedsOfUserParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, transferFeedsOfUser, arg.UserID, arg.UpdatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateFeedMetadata = `-- name: UpdateFeedMetadata :exec
+UPDATE feeds
+SET updated_at = ?2,
+	site_link = ?3,
+	description = ?4,
+	language = ?5,
+	image_url = ?6,
+	generator = ?7,
+	last_build_date = ?8
+WHERE id = ?1
+`
+
+type UpdateFeedMetadataParams struct {
+	ID            string
+	UpdatedAt     time.Time
+	SiteLink      sql.NullString
+	Description   sql.NullString
+	Language      sql.NullString
+	ImageUrl      sql.NullString
+	Generator     sql.NullString
+	LastBuildDate sql.NullTime
+}
+
+func (q *Queries) UpdateFeedMetadata(ctx context.Context, arg UpdateFeedMetadataParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedMetadata,
+		arg.ID,
+		arg.UpdatedAt,
+		arg.SiteLink,
+		arg.Description,
+		arg.Language,
+		arg.ImageUrl,
+		arg.Generator,
+		arg.LastBuildDate,
+	)
+	return err
+}
+
+const updateFeedName = `-- name: UpdateFeedName :one
+UPDATE feeds
+SET name = ?2, updated_at = ?3
+WHERE id = ?1
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, site_link, description, language, image_url, generator, last_build_date, archived_at, last_attempted_at
+`
+
+type UpdateFeedNameParams struct {
+	ID        string
+	Name      string
+	UpdatedAt time.Time
+}
+
+func (q *Queries) UpdateFeedName(ctx context.Context, arg UpdateFeedNameParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, updateFeedName, arg.ID, arg.Name, arg.UpdatedAt)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.SiteLink,
+		&i.Description,
+		&i.Language,
+		&i.ImageUrl,
+		&i.Generator,
+		&i.LastBuildDate,
+		&i.ArchivedAt,
+		&i.LastAttemptedAt,
+	)
+	return i, err
+}
+
+const updateFeedURL = `-- name: UpdateFeedURL :one
+UPDATE feeds
+SET url = ?2, updated_at = ?3
+WHERE id = ?1
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, site_link, description, language, image_url, generator, last_build_date, archived_at, last_attempted_at
+`
+
+type UpdateFeedURLParams struct {
+	ID        string
+	Url       string
+	UpdatedAt time.Time
+}
+
+func (q *Queries) UpdateFeedURL(ctx context.Context, arg UpdateFeedURLParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, updateFeedURL, arg.ID, arg.Url, arg.UpdatedAt)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.SiteLink,
+		&i.Description,
+		&i.Language,
+		&i.ImageUrl,
+		&i.Generator,
+		&i.LastBuildDate,
+		&i.ArchivedAt,
+		&i.LastAttemptedAt,
 	)
 	return i, err
 }
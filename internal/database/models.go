@@ -10,21 +10,42 @@ import (
 )
 
 type Feed struct {
+	ID              string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Name            string
+	Url             string
+	UserID          sql.NullString
+	LastFetchedAt   sql.NullTime
+	SiteLink        sql.NullString
+	Description     sql.NullString
+	Language        sql.NullString
+	ImageUrl        sql.NullString
+	Generator       sql.NullString
+	LastBuildDate   sql.NullTime
+	ArchivedAt      sql.NullTime
+	LastAttemptedAt sql.NullTime
+}
+
+type FeedFollow struct {
 	ID            string
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
-	Name          string
-	Url           string
 	UserID        string
-	LastFetchedAt sql.NullTime
+	FeedID        string
+	DisplayName   sql.NullString
+	UnreadStories int64
 }
 
-type FeedFollow struct {
+type Filter struct {
 	ID        string
 	CreatedAt time.Time
 	UpdatedAt time.Time
-	UserID    string
-	FeedID    string
+	UserID    sql.NullString
+	Field     string
+	MatchType string
+	Pattern   string
+	Action    string
 }
 
 type Post struct {
@@ -36,6 +57,39 @@ type Post struct {
 	Description sql.NullString
 	PublishedAt sql.NullTime
 	FeedID      string
+	StoryID     string
+	Fingerprint sql.NullInt64
+	Authors     string
+	Content     sql.NullString
+}
+
+type PostCategory struct {
+	PostID   string
+	Category string
+}
+
+type PostRead struct {
+	UserID string
+	PostID string
+	ReadAt time.Time
+}
+
+type PostSource struct {
+	PostID    string
+	FeedID    string
+	CreatedAt time.Time
+	StoryID   string
+}
+
+type PostStar struct {
+	UserID    string
+	PostID    string
+	StarredAt time.Time
+}
+
+type PrunedPost struct {
+	Url      string
+	PrunedAt time.Time
 }
 
 type User struct {
@@ -11,8 +11,74 @@ import (
 	"time"
 )
 
+const addPostCategory = `-- name: AddPostCategory :exec
+INSERT OR IGNORE INTO post_categories (post_id, category)
+VALUES (
+	?1,
+	?2
+)
+`
+
+type AddPostCategoryParams struct {
+	PostID   string
+	Category string
+}
+
+func (q *Queries) AddPostCategory(ctx context.Context, arg AddPostCategoryParams) error {
+	_, err := q.db.ExecContext(ctx, addPostCategory, arg.PostID, arg.Category)
+	return err
+}
+
+const addPostSource = `-- name: AddPostSource :exec
+INSERT OR IGNORE INTO post_sources (post_id, feed_id, created_at)
+VALUES (
+	?1,
+	?2,
+	?3
+)
+`
+
+type AddPostSourceParams struct {
+	PostID    string
+	FeedID    string
+	CreatedAt time.Time
+}
+
+func (q *Queries) AddPostSource(ctx context.Context, arg AddPostSourceParams) error {
+	_, err := q.db.ExecContext(ctx, addPostSource, arg.PostID, arg.FeedID, arg.CreatedAt)
+	return err
+}
+
+const countPostsOfFeed = `-- name: CountPostsOfFeed :one
+SELECT COUNT(*)
+FROM posts
+WHERE feed_id = ?1
+`
+
+func (q *Queries) CountPostsOfFeed(ctx context.Context, feedID string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countPostsOfFeed, feedID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countStarredPostsOfFeed = `-- name: CountStarredPostsOfFeed :one
+SELECT COUNT(DISTINCT post_stars.post_id)
+FROM post_stars
+INNER JOIN posts
+ON posts.id = post_stars.post_id
+WHERE posts.feed_id = ?1
+`
+
+func (q *Queries) CountStarredPostsOfFeed(ctx context.Context, feedID string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countStarredPostsOfFeed, feedID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createPost = `-- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, story_id, fingerprint, authors, content)
 VALUES (
 	?1,
 	?2,
@@ -21,9 +87,13 @@ VALUES (
 	?5,
 	?6,
 	?7,
-	?8
+	?8,
+	?9,
+	?10,
+	?11,
+	?12
 	)
-RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, story_id, fingerprint, authors, content
 `
 
 type CreatePostParams struct {
@@ -35,6 +105,10 @@ type CreatePostParams struct {
 	Description sql.NullString
 	PublishedAt sql.NullTime
 	FeedID      string
+	StoryID     string
+	Fingerprint sql.NullInt64
+	Authors     string
+	Content     sql.NullString
 }
 
 func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
@@ -47,6 +121,10 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		arg.Description,
 		arg.PublishedAt,
 		arg.FeedID,
+		arg.StoryID,
+		arg.Fingerprint,
+		arg.Authors,
+		arg.Content,
 	)
 	var i Post
 	err := row.Scan(
@@ -58,55 +136,160 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		&i.Description,
 		&i.PublishedAt,
 		&i.FeedID,
+		&i.StoryID,
+		&i.Fingerprint,
+		&i.Authors,
+		&i.Content,
 	)
 	return i, err
 }
 
-const getPostsForUser = `-- name: GetPostsForUser :many
-SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id,
-	users.name AS user_name,
-	feeds.name AS feed_name
-FROM posts
-INNER JOIN feed_follows
-ON posts.feed_id = feed_follows.feed_id
-INNER JOIN users
-ON feed_follows.user_id = users.id
-INNER JOIN feeds
-ON feeds.id = feed_follows.feed_id
-WHERE users.id = ?1
-AND feeds.name LIKE '%' || ?3 || '%'
-ORDER BY published_at DESC
-LIMIT ?2
+const deleteAllPosts = `-- name: DeleteAllPosts :execrows
+DELETE FROM posts
 `
 
-type GetPostsForUserParams struct {
-	ID        string
-	Limit     int64
-	FeedQuery sql.NullString
+func (q *Queries) DeleteAllPosts(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteAllPosts)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
-type GetPostsForUserRow struct {
-	ID          string
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description sql.NullString
-	PublishedAt sql.NullTime
-	FeedID      string
-	UserName    string
-	FeedName    string
+const deletePostCategories = `-- name: DeletePostCategories :exec
+DELETE FROM post_categories
+WHERE post_id = ?1
+`
+
+func (q *Queries) DeletePostCategories(ctx context.Context, postID string) error {
+	_, err := q.db.ExecContext(ctx, deletePostCategories, postID)
+	return err
+}
+
+const deletePostsBefore = `-- name: DeletePostsBefore :execrows
+DELETE FROM posts
+WHERE datetime(COALESCE(published_at, created_at)) < datetime(?1)
+AND NOT EXISTS (
+	SELECT 1
+	FROM post_stars
+	WHERE post_stars.post_id = posts.id
+)
+`
+
+func (q *Queries) DeletePostsBefore(ctx context.Context, before interface{}) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deletePostsBefore, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
-func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]GetPostsForUserRow, error) {
-	rows, err := q.db.QueryContext(ctx, getPostsForUser, arg.ID, arg.Limit, arg.FeedQuery)
+const deletePostsOfFeed = `-- name: DeletePostsOfFeed :execrows
+DELETE FROM posts
+WHERE feed_id = ?1
+`
+
+func (q *Queries) DeletePostsOfFeed(ctx context.Context, feedID string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deletePostsOfFeed, feedID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deletePostSourcesForFeed = `-- name: DeletePostSourcesForFeed :exec
+DELETE FROM post_sources
+WHERE feed_id = ?1
+`
+
+func (q *Queries) DeletePostSourcesForFeed(ctx context.Context, feedID string) error {
+	_, err := q.db.ExecContext(ctx, deletePostSourcesForFeed, feedID)
+	return err
+}
+
+const deletePrunedPosts = `-- name: DeletePrunedPosts :exec
+DELETE FROM pruned_posts
+`
+
+func (q *Queries) DeletePrunedPosts(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deletePrunedPosts)
+	return err
+}
+
+const deleteSharedPostSourcesForFeed = `-- name: DeleteSharedPostSourcesForFeed :exec
+DELETE FROM post_sources
+WHERE feed_id = ?1
+AND NOT EXISTS (
+	SELECT 1
+	FROM posts
+	WHERE posts.id = post_sources.post_id
+	AND posts.feed_id = ?1
+)
+`
+
+func (q *Queries) DeleteSharedPostSourcesForFeed(ctx context.Context, feedID string) error {
+	_, err := q.db.ExecContext(ctx, deleteSharedPostSourcesForFeed, feedID)
+	return err
+}
+
+const deleteUnstarredPostsOfFeed = `-- name: DeleteUnstarredPostsOfFeed :execrows
+DELETE FROM posts
+WHERE feed_id = ?1
+AND NOT EXISTS (
+	SELECT 1
+	FROM post_stars
+	WHERE post_stars.post_id = posts.id
+)
+`
+
+func (q *Queries) DeleteUnstarredPostsOfFeed(ctx context.Context, feedID string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteUnstarredPostsOfFeed, feedID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getPostByURL = `-- name: GetPostByURL :one
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, story_id, fingerprint, authors, content FROM posts
+WHERE url = ?1
+`
+
+func (q *Queries) GetPostByURL(ctx context.Context, url string) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getPostByURL, url)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.StoryID,
+		&i.Fingerprint,
+		&i.Authors,
+		&i.Content,
+	)
+	return i, err
+}
+
+const getPostsByIDPrefix = `-- name: GetPostsByIDPrefix :many
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, story_id, fingerprint, authors, content FROM posts
+WHERE id LIKE ?1 || '%'
+LIMIT 2
+`
+
+func (q *Queries) GetPostsByIDPrefix(ctx context.Context, idPrefix string) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsByIDPrefix, idPrefix)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetPostsForUserRow
+	var items []Post
 	for rows.Next() {
-		var i GetPostsForUserRow
+		var i Post
 		if err := rows.Scan(
 			&i.ID,
 			&i.CreatedAt,
@@ -116,8 +299,10 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
-			&i.UserName,
-			&i.FeedName,
+			&i.StoryID,
+			&i.Fingerprint,
+			&i.Authors,
+			&i.Content,
 		); err != nil {
 			return nil, err
 		}
@@ -131,3 +316,175 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 	}
 	return items, nil
 }
+
+const getRecentFingerprints = `-- name: GetRecentFingerprints :many
+SELECT story_id, fingerprint
+FROM posts
+WHERE fingerprint IS NOT NULL
+AND story_id NOT IN (
+	SELECT story_id
+	FROM post_sources
+	WHERE feed_id = ?1
+)
+ORDER BY created_at DESC
+LIMIT ?2
+`
+
+type GetRecentFingerprintsParams struct {
+	FeedID string
+	Limit  int64
+}
+
+type GetRecentFingerprintsRow struct {
+	StoryID     string
+	Fingerprint sql.NullInt64
+}
+
+func (q *Queries) GetRecentFingerprints(ctx context.Context, arg GetRecentFingerprintsParams) ([]GetRecentFingerprintsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getRecentFingerprints, arg.FeedID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRecentFingerprintsRow
+	for rows.Next() {
+		var i GetRecentFingerprintsRow
+		if err := rows.Scan(&i.StoryID, &i.Fingerprint); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const isPostPruned = `-- name: IsPostPruned :one
+SELECT COUNT(*)
+FROM pruned_posts
+WHERE url = ?1
+`
+
+func (q *Queries) IsPostPruned(ctx context.Context, url string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, isPostPruned, url)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const markPostsPrunedBefore = `-- name: MarkPostsPrunedBefore :exec
+INSERT OR IGNORE INTO pruned_posts (url, pruned_at)
+SELECT url, ?1
+FROM posts
+WHERE datetime(COALESCE(published_at, created_at)) < datetime(?2)
+AND NOT EXISTS (
+	SELECT 1
+	FROM post_stars
+	WHERE post_stars.post_id = posts.id
+)
+`
+
+type MarkPostsPrunedBeforeParams struct {
+	PrunedAt time.Time
+	Before   interface{}
+}
+
+func (q *Queries) MarkPostsPrunedBefore(ctx context.Context, arg MarkPostsPrunedBeforeParams) error {
+	_, err := q.db.ExecContext(ctx, markPostsPrunedBefore, arg.PrunedAt, arg.Before)
+	return err
+}
+
+const movePosts = `-- name: MovePosts :execrows
+UPDATE posts
+SET feed_id = ?1
+WHERE feed_id = ?2
+`
+
+type MovePostsParams struct {
+	ToFeedID   string
+	FromFeedID string
+}
+
+func (q *Queries) MovePosts(ctx context.Context, arg MovePostsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, movePosts, arg.ToFeedID, arg.FromFeedID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const movePostSources = `-- name: MovePostSources :exec
+UPDATE OR IGNORE post_sources
+SET feed_id = ?1
+WHERE feed_id = ?2
+`
+
+type MovePostSourcesParams struct {
+	ToFeedID   string
+	FromFeedID string
+}
+
+func (q *Queries) MovePostSources(ctx context.Context, arg MovePostSourcesParams) error {
+	_, err := q.db.ExecContext(ctx, movePostSources, arg.ToFeedID, arg.FromFeedID)
+	return err
+}
+
+const reassignPostsOfFeed = `-- name: ReassignPostsOfFeed :execrows
+UPDATE posts
+SET feed_id = (
+	SELECT post_sources.feed_id
+	FROM post_sources
+	WHERE post_sources.post_id = posts.id
+	AND post_sources.feed_id != ?1
+	ORDER BY post_sources.created_at
+	LIMIT 1
+)
+WHERE feed_id = ?1
+AND EXISTS (
+	SELECT 1
+	FROM post_sources
+	WHERE post_sources.post_id = posts.id
+	AND post_sources.feed_id != ?1
+)
+`
+
+func (q *Queries) ReassignPostsOfFeed(ctx context.Context, feedID string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, reassignPostsOfFeed, feedID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updatePost = `-- name: UpdatePost :exec
+UPDATE posts
+SET updated_at = ?2, title = ?3, description = ?4, published_at = ?5, authors = ?6, content = ?7
+WHERE id = ?1
+`
+
+type UpdatePostParams struct {
+	ID          string
+	UpdatedAt   time.Time
+	Title       string
+	Description sql.NullString
+	PublishedAt sql.NullTime
+	Authors     string
+	Content     sql.NullString
+}
+
+func (q *Queries) UpdatePost(ctx context.Context, arg UpdatePostParams) error {
+	_, err := q.db.ExecContext(ctx, updatePost,
+		arg.ID,
+		arg.UpdatedAt,
+		arg.Title,
+		arg.Description,
+		arg.PublishedAt,
+		arg.Authors,
+		arg.Content,
+	)
+	return err
+}
@@ -0,0 +1,124 @@
+package database
+
+// Full-text search is written by hand, sqlc doesn't understand FTS5 virtual tables and their functions
+
+import (
+	"context"
+	"database/sql"
+)
+
+const searchAvailable = `SELECT EXISTS (
+	SELECT 1
+	FROM sqlite_master
+	WHERE type = 'table'
+	AND name = 'posts_fts'
+) AND sqlite_compileoption_used('ENABLE_FTS5')
+`
+
+// SearchAvailable reports whether the full-text index exists and can be queried by this build
+func (q *Queries) SearchAvailable(ctx context.Context) (bool, error) {
+	row := q.db.QueryRowContext(ctx, searchAvailable)
+	var available bool
+	err := row.Scan(&available)
+	return available, err
+}
+
+// searchPosts ranks matches with BM25, titles weigh more than descriptions and content, post_id is unindexed and weighs nothing,
+// and keeps the best match of every story
+const searchPosts = `WITH matches AS MATERIALIZED (
+	SELECT post_id,
+		bm25(posts_fts, 0.0, 10.0, 1.0, 1.0) AS rank,
+		snippet(posts_fts, -1, ?2, ?3, '...', 16) AS snippet
+	FROM posts_fts
+	WHERE posts_fts MATCH ?1
+)
+SELECT posts.id, posts.title, posts.url, posts.published_at,
+	matches.snippet,
+	json_group_array(DISTINCT COALESCE(feed_follows.display_name, feeds.name)) AS feed_names,
+	MIN(matches.rank) AS rank
+FROM matches
+INNER JOIN posts
+ON posts.id = matches.post_id
+INNER JOIN post_sources
+ON posts.id = post_sources.post_id
+INNER JOIN feed_follows
+ON post_sources.feed_id = feed_follows.feed_id
+INNER JOIN feeds
+ON feeds.id = post_sources.feed_id
+WHERE feed_follows.user_id = ?4
+AND (?5 IS NULL OR feeds.id = ?5)
+AND (?6 IS NULL OR EXISTS (
+	SELECT 1
+	FROM follow_tags
+	WHERE follow_tags.follow_id = feed_follows.id
+	AND follow_tags.tag = ?6
+))
+AND (?7 IS NULL OR datetime(COALESCE(posts.published_at, posts.created_at)) >= datetime(?7))
+AND (?8 IS NULL OR datetime(COALESCE(posts.published_at, posts.created_at)) < datetime(?8))
+GROUP BY posts.story_id
+ORDER BY rank
+LIMIT ?9
+`
+
+type SearchPostsParams struct {
+	Query          string
+	HighlightStart string
+	HighlightEnd   string
+	UserID         string
+	FeedID         sql.NullString
+	Tag            sql.NullString
+	After          sql.NullTime
+	Before         sql.NullTime
+	Limit          int64
+}
+
+type SearchPostsRow struct {
+	ID          string
+	Title       string
+	Url         string
+	PublishedAt sql.NullTime
+	Snippet     string
+	FeedNames   string
+	Rank        float64
+}
+
+func (q *Queries) SearchPosts(ctx context.Context, arg SearchPostsParams) ([]SearchPostsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchPosts,
+		arg.Query,
+		arg.HighlightStart,
+		arg.HighlightEnd,
+		arg.UserID,
+		arg.FeedID,
+		arg.Tag,
+		arg.After,
+		arg.Before,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchPostsRow
+	for rows.Next() {
+		var i SearchPostsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.PublishedAt,
+			&i.Snippet,
+			&i.FeedNames,
+			&i.Rank,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
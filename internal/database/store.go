@@ -0,0 +1,143 @@
+package database
+
+// Store is written by hand, it's the data-access layer used by the rest of gator, wrapping queries generated by sqlc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// Domain errors returned by Store, the driver error stays wrapped inside, so both may be checked with errors.Is
+var (
+	ErrNotFound         = errors.New("not found")
+	ErrDuplicateUser    = errors.New("user already exists")
+	ErrDuplicateFeed    = errors.New("feed already exists")
+	ErrAlreadyFollowing = errors.New("feed is already followed")
+	ErrDuplicatePost    = errors.New("post already exists")
+	ErrInvalidSearch    = errors.New("invalid search query")
+)
+
+// Store embeds generated queries and translates driver errors of the ones whose failures are handled by callers
+type Store struct {
+	*Queries
+	// db is nil for stores which are already in a transaction
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{
+		Queries: New(db),
+		db:      db,
+	}
+}
+
+// InTx runs fn with a store whose queries are made in a single transaction, committed when fn succeeds and rolled back otherwise,
+// a store which is already in a transaction runs fn within it
+func (s *Store) InTx(ctx context.Context, fn func(*Store) error) error {
+	if s.db == nil {
+		return fn(s)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error while starting transaction - %w\n", err)
+	}
+	if err := fn(&Store{Queries: s.Queries.WithTx(tx)}); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error while committing transaction - %w\n", err)
+	}
+	return nil
+}
+
+func (s *Store) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	user, err := s.Queries.CreateUser(ctx, arg)
+	return user, uniqueErr(err, ErrDuplicateUser)
+}
+
+func (s *Store) RenameUser(ctx context.Context, arg RenameUserParams) (User, error) {
+	user, err := s.Queries.RenameUser(ctx, arg)
+	return user, uniqueErr(err, ErrDuplicateUser)
+}
+
+func (s *Store) GetUser(ctx context.Context, name string) (User, error) {
+	user, err := s.Queries.GetUser(ctx, name)
+	return user, notFoundErr(err)
+}
+
+func (s *Store) GetUserByID(ctx context.Context, id string) (User, error) {
+	user, err := s.Queries.GetUserByID(ctx, id)
+	return user, notFoundErr(err)
+}
+
+func (s *Store) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, error) {
+	feed, err := s.Queries.CreateFeed(ctx, arg)
+	return feed, uniqueErr(err, ErrDuplicateFeed)
+}
+
+func (s *Store) UpdateFeedURL(ctx context.Context, arg UpdateFeedURLParams) (Feed, error) {
+	feed, err := s.Queries.UpdateFeedURL(ctx, arg)
+	return feed, uniqueErr(err, ErrDuplicateFeed)
+}
+
+func (s *Store) GetFeedByURL(ctx context.Context, url string) (Feed, error) {
+	feed, err := s.Queries.GetFeedByURL(ctx, url)
+	return feed, notFoundErr(err)
+}
+
+func (s *Store) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
+	feed, err := s.Queries.GetNextFeedToFetch(ctx)
+	return feed, notFoundErr(err)
+}
+
+func (s *Store) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowParams) (CreateFeedFollowRow, error) {
+	follow, err := s.Queries.CreateFeedFollow(ctx, arg)
+	return follow, uniqueErr(err, ErrAlreadyFollowing)
+}
+
+func (s *Store) GetFeedFollow(ctx context.Context, arg GetFeedFollowParams) (FeedFollow, error) {
+	follow, err := s.Queries.GetFeedFollow(ctx, arg)
+	return follow, notFoundErr(err)
+}
+
+func (s *Store) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
+	post, err := s.Queries.CreatePost(ctx, arg)
+	return post, uniqueErr(err, ErrDuplicatePost)
+}
+
+func (s *Store) GetPostByURL(ctx context.Context, url string) (Post, error) {
+	post, err := s.Queries.GetPostByURL(ctx, url)
+	return post, notFoundErr(err)
+}
+
+// SearchPosts reports syntax errors of FTS5 queries, SQLite gives them no code of their own, only a message
+func (s *Store) SearchPosts(ctx context.Context, arg SearchPostsParams) ([]SearchPostsRow, error) {
+	posts, err := s.Queries.SearchPosts(ctx, arg)
+	if err != nil && strings.Contains(err.Error(), "fts5:") {
+		return posts, fmt.Errorf("%w: %w", ErrInvalidSearch, err)
+	}
+	return posts, err
+}
+
+func notFoundErr(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+	return err
+}
+
+// uniqueErr turns violation of a unique constraint or primary key into given domain error
+func uniqueErr(err error, domainErr error) error {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && (sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique || sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey) {
+		return fmt.Errorf("%w: %w", domainErr, err)
+	}
+	return err
+}
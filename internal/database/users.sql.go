@@ -7,9 +7,22 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 )
 
+const countFeedsOfUser = `-- name: CountFeedsOfUser :one
+SELECT COUNT(*) FROM feeds
+WHERE user_id = ?1
+`
+
+func (q *Queries) CountFeedsOfUser(ctx context.Context, userID sql.NullString) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countFeedsOfUser, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (id, created_at, updated_at, name)
 VALUES (
@@ -45,6 +58,16 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 	return i, err
 }
 
+const deleteUser = `-- name: DeleteUser :exec
+DELETE FROM users
+WHERE id = ?1
+`
+
+func (q *Queries) DeleteUser(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteUser, id)
+	return err
+}
+
 const getUser = `-- name: GetUser :one
 SELECT id, created_at, updated_at, name FROM users
 WHERE name = ?1
@@ -111,6 +134,31 @@ func (q *Queries) GetUsers(ctx context.Context) ([]User, error) {
 	return items, nil
 }
 
+const renameUser = `-- name: RenameUser :one
+UPDATE users
+SET name = ?2, updated_at = ?3
+WHERE id = ?1
+RETURNING id, created_at, updated_at, name
+`
+
+type RenameUserParams struct {
+	ID        string
+	Name      string
+	UpdatedAt time.Time
+}
+
+func (q *Queries) RenameUser(ctx context.Context, arg RenameUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, renameUser, arg.ID, arg.Name, arg.UpdatedAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+	)
+	return i, err
+}
+
 const resetDb = `-- name: ResetDb :exec
 DELETE FROM users
 `
@@ -0,0 +1,99 @@
+package deduplicating
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// MaxDistance is the number of differing fingerprint bits up to which two posts are treated as the same story
+const MaxDistance = 3
+
+// minWords prevents fingerprinting short texts, for which a few words shift the whole fingerprint
+const minWords = 8
+
+const shingleSize = 3
+
+var tagRegexp = regexp.MustCompile(`<[^>]*>`)
+
+type entry struct {
+	storyID string
+	fingerprint uint64
+}
+
+// Index keeps fingerprints of recently saved posts and finds stories which new posts belong to
+type Index struct {
+	entries []entry
+}
+
+// Fingerprint computes SimHash of post title and content, posts with too little text are not fingerprinted
+func Fingerprint(title, content string) (uint64, bool) {
+	words := tokenize(title + " " + tagRegexp.ReplaceAllString(content, " "))
+	if len(words) < minWords {
+		return 0, false
+	}
+
+	var weights [64]int
+	for i := 0; i+shingleSize <= len(words); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words[i:i+shingleSize], " ")))
+		sum := h.Sum64()
+		for bit := range 64 {
+			if sum&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	var fingerprint uint64
+	for bit, w := range weights {
+		if w > 0 {
+			fingerprint |= 1 << bit
+		}
+	}
+
+	return fingerprint, true
+}
+
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+func (idx *Index) Add(storyID string, fingerprint uint64) {
+	idx.entries = append(idx.entries, entry{
+		storyID: storyID,
+		fingerprint: fingerprint,
+	})
+}
+
+// Remove drops every indexed post of the story, so no more posts are matched with it
+func (idx *Index) Remove(storyID string) {
+	idx.entries = slices.DeleteFunc(idx.entries, func(e entry) bool {
+		return e.storyID == storyID
+	})
+}
+
+// Find returns story of the closest indexed post, if it's within MaxDistance
+func (idx *Index) Find(fingerprint uint64) (string, bool) {
+	best := MaxDistance + 1
+	storyID := ""
+	for _, e := range idx.entries {
+		if d := Distance(e.fingerprint, fingerprint); d < best {
+			best = d
+			storyID = e.storyID
+		}
+	}
+
+	return storyID, storyID != ""
+}
+
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
@@ -0,0 +1,119 @@
+package deduplicating_test
+
+import (
+	"testing"
+
+	"github.com/MedrekIT/gator/internal/deduplicating"
+)
+
+const (
+	storyTitle   = "Big news about the quick brown fox"
+	storyContent = "<p>The quick brown fox jumps over the lazy dog again today in the park near the river, " +
+		"witnesses say it was the fastest jump seen in years and the dog did not even wake up</p>"
+)
+
+func TestFingerprint(t *testing.T) {
+	base, ok := deduplicating.Fingerprint(storyTitle, storyContent)
+	if !ok {
+		t.Fatal("story is not fingerprinted")
+	}
+
+	cases := []struct {
+		name    string
+		title   string
+		content string
+		ok      bool
+		similar bool
+	}{
+		{"same text", storyTitle, storyContent, true, true},
+		{"case and punctuation", "BIG NEWS: about the quick, brown fox!", storyContent, true, true},
+		{"markup", storyTitle, "<div><b>The quick brown fox</b> jumps over the lazy dog again today in the park near the river, " +
+			"witnesses say it was the <i>fastest</i> jump seen in years and the dog did not even wake up</div>", true, true},
+		{"other story", "Local council approves the new budget", "<p>After a long debate the council approved next year's budget, " +
+			"which raises spending on schools and roads while cutting administration costs by a tenth</p>", true, false},
+		{"too short", "Short", "<p>only a few words</p>", false, false},
+		{"markup only", "Title", "<p><img src=\"a.png\" alt=\"many words which are not text\"></p>", false, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fingerprint, ok := deduplicating.Fingerprint(c.title, c.content)
+			if ok != c.ok {
+				t.Fatalf("Fingerprint(%q, %q) fingerprinted: %v, want %v", c.title, c.content, ok, c.ok)
+			}
+			if !ok {
+				return
+			}
+			d := deduplicating.Distance(base, fingerprint)
+			if similar := d <= deduplicating.MaxDistance; similar != c.similar {
+				t.Errorf("distance to the story is %d, want similar: %v", d, c.similar)
+			}
+		})
+	}
+}
+
+func TestDistance(t *testing.T) {
+	cases := []struct {
+		a, b uint64
+		want int
+	}{
+		{0, 0, 0},
+		{0b1011, 0b1011, 0},
+		{0b1011, 0b0010, 2},
+		{0, ^uint64(0), 64},
+	}
+	for _, c := range cases {
+		if got := deduplicating.Distance(c.a, c.b); got != c.want {
+			t.Errorf("Distance(%b, %b) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestIndexFind(t *testing.T) {
+	var idx deduplicating.Index
+	idx.Add("story-a", 0b0000_0000)
+	idx.Add("story-b", 0b1111_0000)
+	idx.Add("story-a", 0b0000_0011)
+
+	cases := []struct {
+		name        string
+		fingerprint uint64
+		want        string
+		found       bool
+	}{
+		{"exact", 0b1111_0000, "story-b", true},
+		{"within distance", 0b1110_0001, "story-b", true},
+		{"closest post wins", 0b0000_0111, "story-a", true},
+		{"at max distance", 0b0111_0000_0000, "story-a", true},
+		{"too far", 0b1111_0000_0000, "", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, found := idx.Find(c.fingerprint)
+			if got != c.want || found != c.found {
+				t.Errorf("Find(%b) = %q, %v, want %q, %v", c.fingerprint, got, found, c.want, c.found)
+			}
+		})
+	}
+
+	var empty deduplicating.Index
+	if got, found := empty.Find(0); found {
+		t.Errorf("empty index found story %q", got)
+	}
+}
+
+func TestIndexRemove(t *testing.T) {
+	var idx deduplicating.Index
+	idx.Add("story-a", 0b0000)
+	idx.Add("story-b", 0b1000)
+	idx.Add("story-a", 0b0001)
+
+	idx.Remove("story-a")
+	if got, found := idx.Find(0b0000); got != "story-b" || !found {
+		t.Errorf("after removing story-a Find = %q, %v, want %q, true", got, found, "story-b")
+	}
+	idx.Remove("story-b")
+	if got, found := idx.Find(0b0000); found {
+		t.Errorf("after removing every story Find = %q, want nothing", got)
+	}
+	idx.Remove("story-c")
+}
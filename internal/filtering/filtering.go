@@ -0,0 +1,172 @@
+package filtering
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"github.com/MedrekIT/gator/internal/database"
+)
+
+const (
+	FieldFeed = "feed"
+	FieldTitle = "title"
+	FieldDescription = "description"
+	FieldAuthor = "author"
+	FieldCategory = "category"
+	FieldURL = "url"
+)
+
+const (
+	MatchSubstring = "substring"
+	MatchRegex = "regex"
+	MatchGlob = "glob"
+)
+
+const (
+	ActionHide = "hide"
+	ActionDrop = "drop"
+)
+
+var Fields = []string{FieldFeed, FieldTitle, FieldDescription, FieldAuthor, FieldCategory, FieldURL}
+var MatchTypes = []string{MatchSubstring, MatchRegex, MatchGlob}
+
+// Item holds the post values which filter rules are matched against
+type Item struct {
+	Feed string
+	Title string
+	Description string
+	Author string
+	Categories []string
+	URL string
+}
+
+type Rule struct {
+	Field string
+	MatchType string
+	Pattern string
+	Action string
+	re *regexp.Regexp
+}
+
+type Rules []Rule
+
+func NewRule(field, matchType, pattern, action string) (Rule, error) {
+	if !slices.Contains(Fields, field) {
+		return Rule{}, fmt.Errorf("unknown filter field \"%s\", expected one of: %s\n", field, strings.Join(Fields, ", "))
+	}
+	if action != ActionHide && action != ActionDrop {
+		return Rule{}, fmt.Errorf("unknown filter action \"%s\"\n", action)
+	}
+	if pattern == "" {
+		return Rule{}, fmt.Errorf("filter pattern cannot be empty\n")
+	}
+
+	r := Rule{
+		Field: field,
+		MatchType: matchType,
+		Pattern: pattern,
+		Action: action,
+	}
+
+	var err error
+	switch matchType {
+	case MatchSubstring:
+		r.Pattern = strings.ToLower(pattern)
+	case MatchRegex:
+		r.re, err = regexp.Compile(pattern)
+		if err != nil {
+			return Rule{}, fmt.Errorf("incorrect regular expression - %w\n", err)
+		}
+	case MatchGlob:
+		r.re, err = globToRegexp(pattern)
+		if err != nil {
+			return Rule{}, fmt.Errorf("incorrect glob pattern - %w\n", err)
+		}
+	default:
+		return Rule{}, fmt.Errorf("unknown match type \"%s\", expected one of: %s\n", matchType, strings.Join(MatchTypes, ", "))
+	}
+
+	return r, nil
+}
+
+// FromDatabase compiles stored filters into rules, stored rules were validated on creation so any error means corrupted data
+func FromDatabase(filters []database.Filter) (Rules, error) {
+	rules := make(Rules, 0, len(filters))
+	for _, f := range filters {
+		r, err := NewRule(f.Field, f.MatchType, f.Pattern, f.Action)
+		if err != nil {
+			return nil, fmt.Errorf("error while compiling filter %s - %w", f.ID, err)
+		}
+		rules = append(rules, r)
+	}
+
+	return rules, nil
+}
+
+// Matches reports whether the rule matches given item, rules on categories match when any of the categories matches
+func (r Rule) Matches(it Item) bool {
+	for _, value := range r.values(it) {
+		switch r.MatchType {
+		case MatchSubstring:
+			if strings.Contains(strings.ToLower(value), r.Pattern) {
+				return true
+			}
+		default:
+			if r.re.MatchString(value) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Matches reports whether any of the rules matches given item
+func (rs Rules) Matches(it Item) bool {
+	for _, r := range rs {
+		if r.Matches(it) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r Rule) values(it Item) []string {
+	switch r.Field {
+	case FieldFeed:
+		return []string{it.Feed}
+	case FieldTitle:
+		return []string{it.Title}
+	case FieldDescription:
+		return []string{it.Description}
+	case FieldAuthor:
+		return []string{it.Author}
+	case FieldCategory:
+		return it.Categories
+	case FieldURL:
+		return []string{it.URL}
+	}
+
+	return nil
+}
+
+// globToRegexp translates shell-like glob into case-insensitive regexp, '*' matches any run of characters (including '/') and '?' exactly one
+func globToRegexp(pattern string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("(?is)^")
+	for _, ch := range pattern {
+		switch ch {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	sb.WriteString("$")
+
+	return regexp.Compile(sb.String())
+}
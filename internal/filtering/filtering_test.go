@@ -0,0 +1,117 @@
+package filtering_test
+
+import (
+	"testing"
+
+	"github.com/MedrekIT/gator/internal/filtering"
+)
+
+func TestNewRule(t *testing.T) {
+	cases := []struct {
+		name      string
+		field     string
+		matchType string
+		pattern   string
+		action    string
+		wantErr   bool
+	}{
+		{"substring", filtering.FieldTitle, filtering.MatchSubstring, "Sponsored", filtering.ActionHide, false},
+		{"regex", filtering.FieldURL, filtering.MatchRegex, `^https?://ads\.`, filtering.ActionDrop, false},
+		{"glob", filtering.FieldFeed, filtering.MatchGlob, "*news*", filtering.ActionHide, false},
+		{"unknown field", "body", filtering.MatchSubstring, "x", filtering.ActionHide, true},
+		{"unknown match type", filtering.FieldTitle, "fuzzy", "x", filtering.ActionHide, true},
+		{"unknown action", filtering.FieldTitle, filtering.MatchSubstring, "x", "delete", true},
+		{"empty pattern", filtering.FieldTitle, filtering.MatchSubstring, "", filtering.ActionHide, true},
+		{"incorrect regex", filtering.FieldTitle, filtering.MatchRegex, "(unclosed", filtering.ActionHide, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := filtering.NewRule(c.field, c.matchType, c.pattern, c.action)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("NewRule(%q, %q, %q, %q) error = %v, want error: %v", c.field, c.matchType, c.pattern, c.action, err, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestRuleMatches(t *testing.T) {
+	item := filtering.Item{
+		Feed:        "Tech News",
+		Title:       "Sponsored: Buy Stuff",
+		Description: "A <b>great</b> deal",
+		Author:      "Jane Doe",
+		Categories:  []string{"Go", "Release Notes"},
+		URL:         "https://ads.example.com/deal?id=1",
+	}
+
+	cases := []struct {
+		name      string
+		field     string
+		matchType string
+		pattern   string
+		want      bool
+	}{
+		{"substring ignores case", filtering.FieldTitle, filtering.MatchSubstring, "sponsored", true},
+		{"substring in the middle", filtering.FieldDescription, filtering.MatchSubstring, "GREAT", true},
+		{"substring missing", filtering.FieldTitle, filtering.MatchSubstring, "free", false},
+		{"substring of another field", filtering.FieldFeed, filtering.MatchSubstring, "sponsored", false},
+		{"regex", filtering.FieldURL, filtering.MatchRegex, `^https?://ads\.`, true},
+		{"regex is case sensitive", filtering.FieldTitle, filtering.MatchRegex, "^sponsored", false},
+		{"regex with flags", filtering.FieldTitle, filtering.MatchRegex, "(?i)^sponsored", true},
+		{"glob", filtering.FieldFeed, filtering.MatchGlob, "*news", true},
+		{"glob ignores case", filtering.FieldFeed, filtering.MatchGlob, "tech*", true},
+		{"glob matches whole value", filtering.FieldFeed, filtering.MatchGlob, "tech", false},
+		{"glob question mark", filtering.FieldFeed, filtering.MatchGlob, "Tech?News", true},
+		{"glob star crosses slashes", filtering.FieldURL, filtering.MatchGlob, "https://ads.*", true},
+		{"glob escapes metacharacters", filtering.FieldURL, filtering.MatchGlob, "*deal?id=1", true},
+		{"author", filtering.FieldAuthor, filtering.MatchSubstring, "jane", true},
+		{"author missing", filtering.FieldAuthor, filtering.MatchSubstring, "john", false},
+		{"any category", filtering.FieldCategory, filtering.MatchGlob, "release*", true},
+		{"category regex", filtering.FieldCategory, filtering.MatchRegex, "^Go$", true},
+		{"no category", filtering.FieldCategory, filtering.MatchSubstring, "rust", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r, err := filtering.NewRule(c.field, c.matchType, c.pattern, filtering.ActionHide)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := r.Matches(item); got != c.want {
+				t.Errorf("%s %s rule %q matches %v, want %v", c.field, c.matchType, c.pattern, got, c.want)
+			}
+		})
+	}
+}
+
+func TestRulesMatches(t *testing.T) {
+	title, err := filtering.NewRule(filtering.FieldTitle, filtering.MatchSubstring, "sponsored", filtering.ActionHide)
+	if err != nil {
+		t.Fatal(err)
+	}
+	author, err := filtering.NewRule(filtering.FieldAuthor, filtering.MatchGlob, "bot *", filtering.ActionDrop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := filtering.Rules{title, author}
+
+	cases := []struct {
+		name string
+		item filtering.Item
+		want bool
+	}{
+		{"first rule", filtering.Item{Title: "Sponsored post"}, true},
+		{"second rule", filtering.Item{Title: "Release", Author: "Bot Smith"}, true},
+		{"none", filtering.Item{Title: "Release", Author: "Jane"}, false},
+		{"empty item", filtering.Item{}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := rules.Matches(c.item); got != c.want {
+				t.Errorf("rules match %+v: %v, want %v", c.item, got, c.want)
+			}
+		})
+	}
+	if (filtering.Rules{}).Matches(filtering.Item{Title: "anything"}) {
+		t.Error("empty rules match an item")
+	}
+}
@@ -0,0 +1,180 @@
+package querying
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"github.com/MedrekIT/gator/internal/database"
+)
+
+const (
+	KeyFeed = "feed"
+	KeyTag = "tag"
+	KeyTitle = "title"
+	KeyAuthor = "author"
+	KeyCategory = "category"
+	KeyAfter = "after"
+	KeyBefore = "before"
+	KeyIs = "is"
+)
+
+var Keys = []string{KeyFeed, KeyTag, KeyTitle, KeyAuthor, KeyCategory, KeyAfter, KeyBefore, KeyIs}
+
+// dateLayouts lists accepted date formats, dates without time mean midnight of local time
+var dateLayouts = []string{
+	time.RFC3339,
+	time.DateTime,
+	time.DateOnly,
+}
+
+// Parse compiles browse query into filter, terms are "key:value" pairs or plain words matched against post title and description,
+// values containing spaces have to be quoted, e.g. title:"go 1.25"
+func Parse(query string) (database.BrowseFilter, error) {
+	var f database.BrowseFilter
+	terms, err := tokenize(query)
+	if err != nil {
+		return f, err
+	}
+
+	for _, term := range terms {
+		key, value, ok := strings.Cut(term, ":")
+		if !ok || !isKey(key) {
+			f.Text = append(f.Text, unquote(term))
+			continue
+		}
+
+		value = unquote(value)
+		if value == "" {
+			return f, fmt.Errorf("missing value of \"%s:\" in query\n", key)
+		}
+		switch strings.ToLower(key) {
+		case KeyFeed:
+			f.Feeds = append(f.Feeds, value)
+		case KeyTag:
+			f.Tags = append(f.Tags, strings.ToLower(value))
+		case KeyTitle:
+			f.Titles = append(f.Titles, value)
+		case KeyAuthor:
+			f.Authors = append(f.Authors, value)
+		case KeyCategory:
+			f.Categories = append(f.Categories, value)
+		case KeyAfter:
+			t, err := ParseDate(value)
+			if err != nil {
+				return f, err
+			}
+			f.After = sql.NullTime{
+				Time: t,
+				Valid: true,
+			}
+		case KeyBefore:
+			t, err := ParseDate(value)
+			if err != nil {
+				return f, err
+			}
+			f.Before = sql.NullTime{
+				Time: t,
+				Valid: true,
+			}
+		case KeyIs:
+			switch strings.ToLower(value) {
+			case "unread":
+				f.Read = sql.NullBool{
+					Bool: false,
+					Valid: true,
+				}
+			case "read":
+				f.Read = sql.NullBool{
+					Bool: true,
+					Valid: true,
+				}
+			case "starred":
+				f.Starred = true
+			default:
+				return f, fmt.Errorf("unknown state \"is:%s\", expected one of: is:unread, is:read, is:starred\n", value)
+			}
+		}
+	}
+
+	return f, nil
+}
+
+func ParseDate(value string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("incorrect date \"%s\", use YYYY-MM-DD, \"YYYY-MM-DD hh:mm:ss\" or RFC 3339 format\n", value)
+}
+
+// EncodeCursor turns the last browsed post into an opaque value for the next page
+func EncodeCursor(post database.BrowsePostsRow) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(post.SortKey + "|" + post.ID))
+}
+
+func DecodeCursor(cursor string) (*database.BrowseCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("incorrect cursor \"%s\"\n", cursor)
+	}
+	sortKey, id, ok := strings.Cut(string(data), "|")
+	if !ok {
+		return nil, fmt.Errorf("incorrect cursor \"%s\"\n", cursor)
+	}
+	if _, err := time.Parse(time.DateTime, sortKey); err != nil {
+		return nil, fmt.Errorf("incorrect cursor \"%s\"\n", cursor)
+	}
+
+	return &database.BrowseCursor{
+		SortKey: sortKey,
+		ID: id,
+	}, nil
+}
+
+func isKey(key string) bool {
+	for _, k := range Keys {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// tokenize splits query on whitespace outside of double quotes
+func tokenize(query string) ([]string, error) {
+	var terms []string
+	var term strings.Builder
+	quoted := false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			term.WriteRune(r)
+		case unicode.IsSpace(r) && !quoted:
+			if term.Len() != 0 {
+				terms = append(terms, term.String())
+				term.Reset()
+			}
+		default:
+			term.WriteRune(r)
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote in query\n")
+	}
+	if term.Len() != 0 {
+		terms = append(terms, term.String())
+	}
+
+	return terms, nil
+}
+
+func unquote(value string) string {
+	return strings.ReplaceAll(value, "\"", "")
+}
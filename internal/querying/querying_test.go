@@ -0,0 +1,106 @@
+package querying_test
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/MedrekIT/gator/internal/database"
+	"github.com/MedrekIT/gator/internal/querying"
+)
+
+func TestParse(t *testing.T) {
+	unread := sql.NullBool{Bool: false, Valid: true}
+	read := sql.NullBool{Bool: true, Valid: true}
+	date := func(layout, value string) sql.NullTime {
+		t, err := time.ParseInLocation(layout, value, time.Local)
+		if err != nil {
+			panic(err)
+		}
+		return sql.NullTime{Time: t, Valid: true}
+	}
+
+	cases := []struct {
+		name    string
+		query   string
+		want    database.BrowseFilter
+		wantErr bool
+	}{
+		{"empty", "", database.BrowseFilter{}, false},
+		{"only spaces", " \t ", database.BrowseFilter{}, false},
+		{"words", "go  release", database.BrowseFilter{Text: []string{"go", "release"}}, false},
+		{"quoted words", `"go 1.25" notes`, database.BrowseFilter{Text: []string{"go 1.25", "notes"}}, false},
+		{"feeds", "feed:go feed:rust", database.BrowseFilter{Feeds: []string{"go", "rust"}}, false},
+		{"tag lowercased", "tag:Security", database.BrowseFilter{Tags: []string{"security"}}, false},
+		{"quoted value", `title:"zero day"`, database.BrowseFilter{Titles: []string{"zero day"}}, false},
+		{"author and category", `author:"Jane Doe" category:Go`, database.BrowseFilter{Authors: []string{"Jane Doe"}, Categories: []string{"Go"}}, false},
+		{"key ignores case", "FEED:go Is:Unread", database.BrowseFilter{Feeds: []string{"go"}, Read: unread}, false},
+		{"unknown key is a word", "lang:go", database.BrowseFilter{Text: []string{"lang:go"}}, false},
+		{"value with colon", "title:re:invent", database.BrowseFilter{Titles: []string{"re:invent"}}, false},
+		{"date", "after:2025-01-01", database.BrowseFilter{After: date(time.DateOnly, "2025-01-01")}, false},
+		{"date and time", `before:"2025-01-01 12:30:00"`, database.BrowseFilter{Before: date(time.DateTime, "2025-01-01 12:30:00")}, false},
+		{"rfc 3339", "after:2025-01-01T12:30:00Z", database.BrowseFilter{After: date(time.RFC3339, "2025-01-01T12:30:00Z")}, false},
+		{"read", "is:read", database.BrowseFilter{Read: read}, false},
+		{"starred", "is:starred", database.BrowseFilter{Starred: true}, false},
+		{"everything", `tag:news is:unread after:2025-01-01 title:"zero day" patch`, database.BrowseFilter{
+			Tags:   []string{"news"},
+			Titles: []string{"zero day"},
+			Text:   []string{"patch"},
+			After:  date(time.DateOnly, "2025-01-01"),
+			Read:   unread,
+		}, false},
+		{"missing value", "feed:", database.BrowseFilter{}, true},
+		{"empty quoted value", `title:""`, database.BrowseFilter{}, true},
+		{"incorrect date", "after:yesterday", database.BrowseFilter{}, true},
+		{"unknown state", "is:archived", database.BrowseFilter{}, true},
+		{"unterminated quote", `title:"zero day`, database.BrowseFilter{}, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := querying.Parse(c.query)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("Parse(%q) error = %v, want error: %v", c.query, err, c.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("Parse(%q) = %+v, want %+v", c.query, got, c.want)
+			}
+		})
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	encode := func(value string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(value))
+	}
+
+	cases := []struct {
+		name    string
+		cursor  string
+		want    *database.BrowseCursor
+		wantErr bool
+	}{
+		{"encoded post", querying.EncodeCursor(database.BrowsePostsRow{ID: "post-1", SortKey: "2025-01-01 12:30:00"}), &database.BrowseCursor{SortKey: "2025-01-01 12:30:00", ID: "post-1"}, false},
+		{"id with separator", encode("2025-01-01 12:30:00|a|b"), &database.BrowseCursor{SortKey: "2025-01-01 12:30:00", ID: "a|b"}, false},
+		{"not base64", "not a cursor!", nil, true},
+		{"no separator", encode("2025-01-01 12:30:00"), nil, true},
+		{"incorrect sort key", encode("yesterday|post"), nil, true},
+		{"date without time", encode("2025-01-01|post"), nil, true},
+		{"empty", "", nil, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := querying.DecodeCursor(c.cursor)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("DecodeCursor(%q) error = %v, want error: %v", c.cursor, err, c.wantErr)
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("DecodeCursor(%q) = %+v, want %+v", c.cursor, got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package rewriting
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// feedHostAliases maps hosts serving the same feeds onto one of them
+var feedHostAliases = map[string]string{
+	"feeds2.feedburner.com": "feeds.feedburner.com",
+	"feedproxy.google.com": "feeds.feedburner.com",
+}
+
+// NormalizeFeedURL canonicalizes feed URL and additionally drops trailing slashes and resolves known host aliases, URLs without scheme are treated as https
+func NormalizeFeedURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+
+	u, err := url.Parse(CanonicalURL(raw))
+	if err != nil {
+		return "", fmt.Errorf("incorrect feed URL - %w\n", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("incorrect feed URL \"%s\", only http and https URLs are supported\n", raw)
+	}
+
+	if alias, ok := feedHostAliases[u.Host]; ok {
+		u.Host = alias
+	}
+	if u.Path != "/" {
+		u.Path = strings.TrimRight(u.Path, "/")
+	}
+
+	return u.String(), nil
+}
+
+// FeedURLVariants returns normalized feed URL with both http and https schemes, the preferred (given) one first
+func FeedURLVariants(normalized string) []string {
+	if rest, ok := strings.CutPrefix(normalized, "https://"); ok {
+		return []string{normalized, "http://" + rest}
+	}
+	if rest, ok := strings.CutPrefix(normalized, "http://"); ok {
+		return []string{normalized, "https://" + rest}
+	}
+
+	return []string{normalized}
+}
+
+// FeedURLKey identifies feed regardless of its scheme, feeds with equal keys are duplicates
+func FeedURLKey(raw string) (string, error) {
+	normalized, err := NormalizeFeedURL(raw)
+	if err != nil {
+		return "", err
+	}
+
+	_, key, _ := strings.Cut(normalized, "://")
+	return key, nil
+}
@@ -0,0 +1,88 @@
+package rewriting
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNormalizeFeedURL(t *testing.T) {
+	cases := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{"unchanged", "https://example.com/feed.xml", "https://example.com/feed.xml", false},
+		{"without scheme", "example.com/feed", "https://example.com/feed", false},
+		{"surrounding spaces", "  http://example.com/rss  ", "http://example.com/rss", false},
+		{"canonicalized", "HTTP://Example.com:80/Feed#top", "http://example.com/Feed", false},
+		{"trailing slashes", "https://example.com/feed//", "https://example.com/feed", false},
+		{"root kept", "https://example.com/", "https://example.com/", false},
+		{"root added", "https://example.com", "https://example.com/", false},
+		{"query kept", "https://example.com/feed/?format=rss", "https://example.com/feed?format=rss", false},
+		{"feedburner alias", "http://feeds2.feedburner.com/example", "http://feeds.feedburner.com/example", false},
+		{"feedproxy alias", "https://feedproxy.google.com/example/", "https://feeds.feedburner.com/example", false},
+		{"unsupported scheme", "ftp://example.com/feed", "", true},
+		{"no host", "https:///feed", "", true},
+		{"not a URL", "https://exa mple.com/%zz", "", true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := NormalizeFeedURL(c.raw)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("NormalizeFeedURL(%q) error = %v, want error: %v", c.raw, err, c.wantErr)
+			}
+			if got != c.want {
+				t.Errorf("NormalizeFeedURL(%q) = %q, want %q", c.raw, got, c.want)
+			}
+		})
+	}
+}
+
+func TestFeedURLVariants(t *testing.T) {
+	cases := []struct {
+		normalized string
+		want       []string
+	}{
+		{"https://example.com/feed", []string{"https://example.com/feed", "http://example.com/feed"}},
+		{"http://example.com/feed", []string{"http://example.com/feed", "https://example.com/feed"}},
+		{"example.com/feed", []string{"example.com/feed"}},
+	}
+	for _, c := range cases {
+		t.Run(c.normalized, func(t *testing.T) {
+			if got := FeedURLVariants(c.normalized); !slices.Equal(got, c.want) {
+				t.Errorf("FeedURLVariants(%q) = %q, want %q", c.normalized, got, c.want)
+			}
+		})
+	}
+}
+
+func TestFeedURLKey(t *testing.T) {
+	cases := []struct {
+		name string
+		a    string
+		b    string
+		same bool
+	}{
+		{"schemes", "http://example.com/feed", "https://example.com/feed", true},
+		{"spelling", "Example.com/feed/", "https://example.com:443/feed", true},
+		{"aliases", "https://feedproxy.google.com/x", "http://feeds.feedburner.com/x", true},
+		{"paths", "https://example.com/feed", "https://example.com/Feed", false},
+		{"queries", "https://example.com/feed?a=1", "https://example.com/feed?a=2", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			a, err := FeedURLKey(c.a)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := FeedURLKey(c.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (a == b) != c.same {
+				t.Errorf("FeedURLKey(%q) = %q and FeedURLKey(%q) = %q, want equal: %v", c.a, a, c.b, b, c.same)
+			}
+		})
+	}
+}
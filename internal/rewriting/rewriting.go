@@ -0,0 +1,292 @@
+package rewriting
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+const maxRedirects = 5
+
+// Rules describes rewrites applied to every fetched item, ResolveRedirects is opt-in
+// as it sends a request for every redirector link without a target in its query
+type Rules struct {
+	StripParams []string `json:"strip_params,omitempty"`
+	UnwrapHosts []string `json:"unwrap_hosts,omitempty"`
+	TitlePrefixes []string `json:"title_prefixes,omitempty"`
+	ResolveRedirects bool `json:"resolve_redirects,omitempty"`
+}
+
+// FeedRules adjust global rules for a single feed, lists extend global ones unless Replace is set,
+// ResolveRedirects overrides the global setting only when it is given, so it may be turned off as well
+type FeedRules struct {
+	StripParams []string `json:"strip_params,omitempty"`
+	UnwrapHosts []string `json:"unwrap_hosts,omitempty"`
+	TitlePrefixes []string `json:"title_prefixes,omitempty"`
+	ResolveRedirects *bool `json:"resolve_redirects,omitempty"`
+	Replace bool `json:"replace,omitempty"`
+}
+
+// Config is kept in the config file, missing global rules fall back to DefaultRules, feeds are keyed by their URL
+type Config struct {
+	Global *Rules `json:"global,omitempty"`
+	Feeds map[string]FeedRules `json:"feeds,omitempty"`
+}
+
+// Item holds values of a parsed item which may be rewritten
+type Item struct {
+	Title string
+	Link string
+	OrigLink string
+	Base string
+}
+
+type Rewriter struct {
+	rules Rules
+	client *http.Client
+}
+
+func DefaultRules() Rules {
+	return Rules{
+		StripParams: []string{"utm_*", "fbclid", "gclid", "mc_cid", "mc_eid", "_hsenc", "_hsmi"},
+		UnwrapHosts: []string{"feedproxy.google.com", "feeds.feedburner.com", "t.co", "lnkd.in", "l.facebook.com", "out.reddit.com"},
+	}
+}
+
+// For returns rules which should be used for feed with given URL
+func (c Config) For(feedURL string) Rules {
+	rules := DefaultRules()
+	if c.Global != nil {
+		rules = *c.Global
+	}
+
+	feedRules, ok := c.Feeds[feedURL]
+	if !ok {
+		return rules
+	}
+	if feedRules.Replace {
+		rules = Rules{}
+	}
+	resolveRedirects := rules.ResolveRedirects
+	if feedRules.ResolveRedirects != nil {
+		resolveRedirects = *feedRules.ResolveRedirects
+	}
+	return Rules{
+		StripParams: append(slices.Clone(rules.StripParams), feedRules.StripParams...),
+		UnwrapHosts: append(slices.Clone(rules.UnwrapHosts), feedRules.UnwrapHosts...),
+		TitlePrefixes: append(slices.Clone(rules.TitlePrefixes), feedRules.TitlePrefixes...),
+		ResolveRedirects: resolveRedirects,
+	}
+}
+
+func New(rules Rules) *Rewriter {
+	return &Rewriter{
+		rules: rules,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Rewrite resolves, unwraps and cleans item link and trims its title, links which cannot be parsed are left untouched
+func (rw *Rewriter) Rewrite(ctx context.Context, it Item) Item {
+	it.Title = rw.trimTitle(it.Title)
+
+	link := strings.TrimSpace(it.Link)
+	if it.OrigLink != "" {
+		link = strings.TrimSpace(it.OrigLink)
+	}
+	if link == "" {
+		return it
+	}
+	if base, err := url.Parse(it.Base); err == nil && it.Base != "" {
+		if ref, err := url.Parse(link); err == nil {
+			link = base.ResolveReference(ref).String()
+		}
+	}
+
+	link = rw.unwrap(ctx, link)
+	link = rw.stripParams(link)
+	it.Link = CanonicalURL(link)
+	it.OrigLink = ""
+
+	return it
+}
+
+// ResolveBase resolves chain of base URLs (feed URL, channel link, xml:base attributes...) from outermost to innermost
+func ResolveBase(bases ...string) string {
+	var resolved *url.URL
+	for _, b := range bases {
+		b = strings.TrimSpace(b)
+		if b == "" {
+			continue
+		}
+		u, err := url.Parse(b)
+		if err != nil {
+			continue
+		}
+		if resolved != nil {
+			u = resolved.ResolveReference(u)
+		}
+		resolved = u
+	}
+
+	if resolved == nil {
+		return ""
+	}
+	return resolved.String()
+}
+
+// CanonicalURL lowercases scheme and host, drops default ports and fragments, so the same resource always gets the same URL,
+// path and query are kept as they are, as servers may tell apart escaped characters or order of parameters
+func CanonicalURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return raw
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	port := u.Port()
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	if port != "" {
+		host = host + ":" + port
+	}
+	u.Host = host
+	u.Fragment = ""
+	u.RawFragment = ""
+	if u.Path == "" {
+		u.Path = "/"
+		u.RawPath = ""
+	}
+	u.ForceQuery = false
+
+	return u.String()
+}
+
+func (rw *Rewriter) trimTitle(title string) string {
+	title = strings.TrimSpace(title)
+	for _, prefix := range rw.rules.TitlePrefixes {
+		if len(title) >= len(prefix) && strings.EqualFold(title[:len(prefix)], prefix) {
+			title = strings.TrimSpace(title[len(prefix):])
+		}
+	}
+
+	return title
+}
+
+// stripParams removes matching parameters from the query, keeping the rest of it untouched and in order
+func (rw *Rewriter) stripParams(link string) string {
+	u, err := url.Parse(link)
+	if err != nil || u.RawQuery == "" {
+		return link
+	}
+
+	var kept []string
+	for _, param := range strings.Split(u.RawQuery, "&") {
+		key, _, _ := strings.Cut(param, "=")
+		if name, err := url.QueryUnescape(key); err == nil && rw.isStripped(name) {
+			continue
+		}
+		kept = append(kept, param)
+	}
+	u.RawQuery = strings.Join(kept, "&")
+
+	return u.String()
+}
+
+func (rw *Rewriter) isStripped(param string) bool {
+	for _, pattern := range rw.rules.StripParams {
+		if ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(param)); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// unwrap replaces redirector links with their target, first by looking for a target in query parameters, then by following redirects without fetching the content
+func (rw *Rewriter) unwrap(ctx context.Context, link string) string {
+	for range maxRedirects {
+		u, err := url.Parse(link)
+		if err != nil || !rw.isRedirector(u.Hostname()) {
+			return link
+		}
+
+		if target := targetFromQuery(u); target != "" {
+			link = target
+			continue
+		}
+		if !rw.rules.ResolveRedirects {
+			return link
+		}
+
+		target, err := rw.follow(ctx, link)
+		if err != nil || target == "" {
+			return link
+		}
+		link = target
+	}
+
+	return link
+}
+
+func (rw *Rewriter) isRedirector(host string) bool {
+	host = strings.ToLower(host)
+	for _, h := range rw.rules.UnwrapHosts {
+		if host == strings.ToLower(h) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (rw *Rewriter) follow(ctx context.Context, link string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "HEAD", link, nil)
+	if err != nil {
+		return "", fmt.Errorf("error while creating request - %w\n", err)
+	}
+	req.Header.Set("user-agent", "gator")
+
+	res, err := rw.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error while fetching response - %w\n", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 300 || res.StatusCode > 399 {
+		return "", nil
+	}
+	location, err := res.Location()
+	if err != nil {
+		return "", err
+	}
+
+	return location.String(), nil
+}
+
+func targetFromQuery(u *url.URL) string {
+	query := u.Query()
+	for _, param := range []string{"url", "u", "q", "dest", "destination", "target", "redirect"} {
+		target := query.Get(param)
+		if strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+			return target
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,164 @@
+package rewriting
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestCanonicalURL(t *testing.T) {
+	cases := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{"unchanged", "https://example.com/post/1", "https://example.com/post/1"},
+		{"lowercase scheme and host", "HTTPS://Example.COM/Post", "https://example.com/Post"},
+		{"default http port", "http://example.com:80/a", "http://example.com/a"},
+		{"default https port", "https://example.com:443/a", "https://example.com/a"},
+		{"other port", "https://example.com:8443/a", "https://example.com:8443/a"},
+		{"port of other scheme", "http://example.com:443/a", "http://example.com:443/a"},
+		{"fragment", "https://example.com/a#comments", "https://example.com/a"},
+		{"empty path", "https://example.com", "https://example.com/"},
+		{"empty query", "https://example.com/a?", "https://example.com/a"},
+		{"query kept in order", "https://example.com/a?b=2&a=1", "https://example.com/a?b=2&a=1"},
+		{"escaped path kept", "https://example.com/a%2Fb", "https://example.com/a%2Fb"},
+		{"ipv6 host", "http://[::1]:80/a", "http://[::1]/a"},
+		{"surrounding spaces", "  https://example.com/a  ", "https://example.com/a"},
+		{"relative", "/post/1", "/post/1"},
+		{"not a URL", "%zz", "%zz"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := CanonicalURL(c.raw); got != c.want {
+				t.Errorf("CanonicalURL(%q) = %q, want %q", c.raw, got, c.want)
+			}
+		})
+	}
+}
+
+func TestStripParams(t *testing.T) {
+	rw := New(DefaultRules())
+
+	cases := []struct {
+		name string
+		link string
+		want string
+	}{
+		{"no query", "https://example.com/a", "https://example.com/a"},
+		{"nothing stripped", "https://example.com/a?id=1&page=2", "https://example.com/a?id=1&page=2"},
+		{"wildcard", "https://example.com/a?utm_source=rss&utm_medium=feed", "https://example.com/a"},
+		{"kept in order", "https://example.com/a?b=2&utm_source=rss&a=1&fbclid=x", "https://example.com/a?b=2&a=1"},
+		{"ignores case", "https://example.com/a?UTM_Source=rss&id=1", "https://example.com/a?id=1"},
+		{"escaped name", "https://example.com/a?utm%5Fsource=rss&id=1", "https://example.com/a?id=1"},
+		{"without value", "https://example.com/a?gclid&id=1", "https://example.com/a?id=1"},
+		{"escaped values kept", "https://example.com/a?q=a%20b&utm_term=x", "https://example.com/a?q=a%20b"},
+		{"fragment kept", "https://example.com/a?utm_source=rss#top", "https://example.com/a#top"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := rw.stripParams(c.link); got != c.want {
+				t.Errorf("stripParams(%q) = %q, want %q", c.link, got, c.want)
+			}
+		})
+	}
+}
+
+func TestUnwrap(t *testing.T) {
+	target := "https://example.com/article"
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/redirect":
+			http.Redirect(w, r, target, http.StatusMovedPermanently)
+		case "/chain":
+			http.Redirect(w, r, "/redirect", http.StatusFound)
+		case "/loop":
+			http.Redirect(w, r, "/loop", http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rules := DefaultRules()
+	rules.UnwrapHosts = append(rules.UnwrapHosts, serverURL.Hostname())
+	resolving := rules
+	resolving.ResolveRedirects = true
+
+	cases := []struct {
+		name     string
+		rules    Rules
+		link     string
+		want     string
+		requests int
+	}{
+		{"not a redirector", rules, "https://example.com/a?url=https://other.com/", "https://example.com/a?url=https://other.com/", 0},
+		{"target in query", rules, "https://l.facebook.com/l.php?u=https%3A%2F%2Fexample.com%2Farticle&h=x", target, 0},
+		{"host ignores case", rules, "https://T.CO/x?url=https://example.com/article", target, 0},
+		{"query without link", rules, "https://t.co/x?u=article", "https://t.co/x?u=article", 0},
+		{"redirect not followed by default", rules, server.URL + "/redirect", server.URL + "/redirect", 0},
+		{"redirect followed", resolving, server.URL + "/redirect", target, 1},
+		{"chain of redirects", resolving, server.URL + "/chain", target, 2},
+		{"no redirect", resolving, server.URL + "/page", server.URL + "/page", 1},
+		{"endless redirects", resolving, server.URL + "/loop", server.URL + "/loop", maxRedirects},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			requests = 0
+			if got := New(c.rules).unwrap(context.Background(), c.link); got != c.want {
+				t.Errorf("unwrap(%q) = %q, want %q", c.link, got, c.want)
+			}
+			if requests != c.requests {
+				t.Errorf("unwrap(%q) sent %d requests, want %d", c.link, requests, c.requests)
+			}
+		})
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	rules := DefaultRules()
+	rules.TitlePrefixes = []string{"[Sponsored]"}
+	rw := New(rules)
+
+	cases := []struct {
+		name string
+		item Item
+		want Item
+	}{
+		{
+			"relative link",
+			Item{Title: " Post ", Link: "../b/post?utm_source=rss#x", Base: "https://Example.com/a/"},
+			Item{Title: "Post", Link: "https://example.com/b/post", Base: "https://Example.com/a/"},
+		},
+		{
+			"original link preferred",
+			Item{Title: "Post", Link: "https://feedproxy.google.com/~r/x", OrigLink: "https://example.com/post"},
+			Item{Title: "Post", Link: "https://example.com/post"},
+		},
+		{
+			"unwrapped and stripped",
+			Item{Title: "[sponsored] Deal", Link: "https://t.co/x?url=https%3A%2F%2Fexample.com%2Fdeal%3Futm_medium%3Dfeed"},
+			Item{Title: "Deal", Link: "https://example.com/deal"},
+		},
+		{
+			"no link",
+			Item{Title: "Post", Base: "https://example.com/"},
+			Item{Title: "Post", Base: "https://example.com/"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := rw.Rewrite(context.Background(), c.item); got != c.want {
+				t.Errorf("Rewrite(%+v) = %+v, want %+v", c.item, got, c.want)
+			}
+		})
+	}
+}
@@ -3,12 +3,11 @@ package main
 import (
 	"os"
 	"log"
-	"database/sql"
 	"github.com/MedrekIT/gator/internal/database"
 	"github.com/MedrekIT/gator/internal/config"
 	"github.com/MedrekIT/gator/internal/commands"
+	"github.com/MedrekIT/gator/internal/connecting"
 	"github.com/MedrekIT/gator/sql"
-	_ "github.com/mattn/go-sqlite3"
 )
 
 func main() {
@@ -17,37 +16,51 @@ func main() {
 		log.Fatalf("\nError: %v", err)
 	}
 
-	db, err := sql.Open("sqlite3", conf.DbPath)
-	if err != nil {
-		log.Fatalf("\nError: %v", err)
+	if len(os.Args) < 2 {
+		log.Fatalf("\nUsage: cli <command> [args...]")
+	}
+	cmds := commands.GetCommands()
+	if _, ok := cmds[os.Args[1]]; !ok {
+		log.Fatalf("\nCommand '%s' not specified\nTry 'help' to see all commands\n", os.Args[1])
+	}
+
+	// 'migrate' manages the schema on its own, so it can also roll it back
+	mode := connecting.Schema
+	if os.Args[1] != "migrate" {
+		schemaDb, err := connecting.Open(conf.DbPath, conf.Database, connecting.Schema)
+		if err != nil {
+			log.Fatalf("\nError: %v", err)
+		}
+		err = embedding.DbEmbedding(schemaDb)
+		schemaDb.Close()
+		if err != nil {
+			log.Fatalf("\nError: %v", err)
+		}
+
+		mode = connecting.ReadWrite
+		if cmds[os.Args[1]].ReadOnly() {
+			mode = connecting.ReadOnly
+		}
 	}
-	defer db.Close()
 
-	err = embedding.DbEmbedding(db)
+	db, err := connecting.Open(conf.DbPath, conf.Database, mode)
 	if err != nil {
 		log.Fatalf("\nError: %v", err)
 	}
+	defer db.Close()
 
-	dbQueries := database.New(db)
-
-	if len(os.Args) < 2 {
-		log.Fatalf("\nUsage: cli <command> [args...]")
-	}
+	dbQueries := database.NewStore(db)
 
 	s := config.State{
 		Db: dbQueries,
+		DbConn: db,
 		Conf: &conf,
 	}
-	cmds := commands.GetCommands()
-
 	cmd := commands.Command{
 		Name: os.Args[1],
 		Args: os.Args[2:],
 	}
 
-	if _, ok := cmds[os.Args[1]]; !ok {
-		log.Fatalf("\nCommand '%s' not specified\nTry 'help' to see all commands\n", os.Args[1])
-	}
 	err = cmds[os.Args[1]].Run(&s, cmd)
 	if err != nil {
 		log.Fatalf("\nError: %v", err)
@@ -1,29 +1,83 @@
 package embedding
 
 import (
+	"context"
 	"embed"
 	"database/sql"
+	"fmt"
+	"io/fs"
 	"github.com/pressly/goose/v3"
 )
 
 //go:embed schema/*.sql
 var embedMigrations embed.FS
 
+// DbEmbedding checks the database schema before any command runs, a fresh database is migrated up silently,
+// while older schemas have to be migrated with 'gator migrate up', so migrations rolled back on purpose aren't applied again,
+// databases migrated by a newer gator are refused, given database has to be opened without foreign keys, as some migrations rebuild tables
 func DbEmbedding(db *sql.DB) error {
-	goose.SetBaseFS(embedMigrations)
-	goose.SetLogger(goose.NopLogger())
-
-	if err := goose.SetDialect("sqlite3"); err != nil {
+	ctx := context.Background()
+	provider, err := newSchemaProvider(ctx, db)
+	if err != nil {
 		return err
 	}
 
-	if err := goose.Up(db, "schema"); err != nil {
+	current, latest, err := checkVersions(ctx, provider)
+	if err != nil {
 		return err
 	}
+	if current == 0 {
+		if _, err := provider.Up(ctx); err != nil {
+			return fmt.Errorf("error while migrating the database - %w\n", err)
+		}
+	} else if current < latest {
+		return fmt.Errorf("database schema version %d is older than the latest version known to this gator (%d), migrate it with 'gator migrate up' first\n", current, latest)
+	}
 
-	if _, err := db.Exec("PRAGMA foreign_keys = ON"); err != nil {
+	if err := ensureSearchIndex(db); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// newSchemaProvider is used by everything that applies or rolls back migrations, it refuses connections with foreign keys on,
+// as dropping a rebuilt table (like feeds in 015_shared_feeds.sql) would then cascade onto every row referencing it
+func newSchemaProvider(ctx context.Context, db *sql.DB) (*goose.Provider, error) {
+	var foreignKeys bool
+	if err := db.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&foreignKeys); err != nil {
+		return nil, fmt.Errorf("error while checking database foreign keys - %w\n", err)
+	}
+	if foreignKeys {
+		return nil, fmt.Errorf("migrations can't be run with foreign keys on, the database has to be opened for schema changes\n")
+	}
+
+	return newProvider(db)
+}
+
+func newProvider(db *sql.DB) (*goose.Provider, error) {
+	schema, err := fs.Sub(embedMigrations, "schema")
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := goose.NewProvider(goose.DialectSQLite3, db, schema)
+	if err != nil {
+		return nil, fmt.Errorf("error while loading migrations - %w\n", err)
+	}
+	return provider, nil
+}
+
+// checkVersions returns version of the database schema and the latest version known to this build,
+// failing when the database has already been migrated further, as this build doesn't know how to use it
+func checkVersions(ctx context.Context, provider *goose.Provider) (int64, int64, error) {
+	current, latest, err := provider.GetVersions(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error while getting database schema version - %w\n", err)
+	}
+
+	if current > latest {
+		return current, latest, fmt.Errorf("database schema version %d is newer than the latest version known to this gator (%d), update gator or roll the database back with 'gator migrate to %d' using the newer one\n", current, latest, latest)
+	}
+	return current, latest, nil
+}
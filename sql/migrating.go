@@ -0,0 +1,157 @@
+package embedding
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path"
+	"time"
+	"github.com/pressly/goose/v3"
+)
+
+// Migration is a single schema file together with its state in the database
+type Migration struct {
+	Version int64
+	Name string
+	Applied bool
+	AppliedAt time.Time
+}
+
+// Step is a migration applied (up) or rolled back (down) by one of migrating functions
+type Step struct {
+	Version int64
+	Name string
+	Up bool
+	Duration time.Duration
+}
+
+// Versions returns version of the database schema and the latest version known to this build
+func Versions(ctx context.Context, db *sql.DB) (int64, int64, error) {
+	provider, err := newProvider(db)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	current, latest, err := provider.GetVersions(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error while getting database schema version - %w\n", err)
+	}
+	return current, latest, nil
+}
+
+func Status(ctx context.Context, db *sql.DB) ([]Migration, error) {
+	provider, err := newProvider(db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses, err := provider.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error while getting migrations status - %w\n", err)
+	}
+
+	migrations := make([]Migration, 0, len(statuses))
+	for _, status := range statuses {
+		migrations = append(migrations, Migration{
+			Version: status.Source.Version,
+			Name: path.Base(status.Source.Path),
+			Applied: status.State == goose.StateApplied,
+			AppliedAt: status.AppliedAt,
+		})
+	}
+	return migrations, nil
+}
+
+// Up applies every pending migration
+func Up(ctx context.Context, db *sql.DB) ([]Step, error) {
+	provider, err := newSchemaProvider(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := checkVersions(ctx, provider); err != nil {
+		return nil, err
+	}
+
+	results, err := provider.Up(ctx)
+	return steps(results), migrationErr(err)
+}
+
+// Down rolls back the latest applied migration
+func Down(ctx context.Context, db *sql.DB) ([]Step, error) {
+	provider, err := newSchemaProvider(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	current, _, err := checkVersions(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+	if current == 0 {
+		return nil, fmt.Errorf("no migrations have been applied to the database\n")
+	}
+
+	result, err := provider.Down(ctx)
+	return steps([]*goose.MigrationResult{result}), migrationErr(err)
+}
+
+// To migrates the database up or down to given version, version 0 rolls back every migration
+func To(ctx context.Context, db *sql.DB, version int64) ([]Step, error) {
+	provider, err := newSchemaProvider(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	current, latest, err := checkVersions(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+	if version < 0 || version > latest {
+		return nil, fmt.Errorf("unknown schema version %d, versions known to this gator are 0-%d\n", version, latest)
+	}
+
+	var results []*goose.MigrationResult
+	switch {
+	case version > current:
+		results, err = provider.UpTo(ctx, version)
+	case version < current:
+		results, err = provider.DownTo(ctx, version)
+	}
+	return steps(results), migrationErr(err)
+}
+
+// Redo rolls back the latest applied migration and applies it again
+func Redo(ctx context.Context, db *sql.DB) ([]Step, error) {
+	done, err := Down(ctx, db)
+	if err != nil {
+		return done, err
+	}
+
+	provider, err := newSchemaProvider(ctx, db)
+	if err != nil {
+		return done, err
+	}
+	result, err := provider.UpByOne(ctx)
+	return append(done, steps([]*goose.MigrationResult{result})...), migrationErr(err)
+}
+
+func steps(results []*goose.MigrationResult) []Step {
+	var done []Step
+	for _, result := range results {
+		if result == nil || result.Error != nil {
+			continue
+		}
+		done = append(done, Step{
+			Version: result.Source.Version,
+			Name: path.Base(result.Source.Path),
+			Up: result.Direction == "up",
+			Duration: result.Duration,
+		})
+	}
+	return done
+}
+
+func migrationErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("error while migrating the database - %w\n", err)
+}
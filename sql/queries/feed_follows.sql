@@ -18,14 +18,80 @@ WHERE feed_follows.feed_id = feeds.id),
 
 -- name: GetFeedFollowsForUser :many
 SELECT users.name AS user_name,
-feeds.name AS feed_name
+sqlc.embed(feeds),
+feed_follows.display_name,
+feed_follows.unread_stories AS unread_count,
+COALESCE((SELECT group_concat(follow_tags.tag)
+FROM follow_tags
+WHERE follow_tags.follow_id = feed_follows.id), '') AS tags
 FROM feed_follows
 INNER JOIN users
 ON feed_follows.user_id = users.id
 INNER JOIN feeds
 ON feed_follows.feed_id = feeds.id
-WHERE users.id = ?1;
+WHERE users.id = ?1
+AND (sqlc.narg(tag) IS NULL OR EXISTS (
+	SELECT 1
+	FROM follow_tags
+	WHERE follow_tags.follow_id = feed_follows.id
+	AND follow_tags.tag = sqlc.narg(tag)
+));
+
+-- name: GetFeedFollow :one
+SELECT * FROM feed_follows
+WHERE user_id = ?1 AND feed_id = ?2;
 
 -- name: DeleteFeedFollow :exec
 DELETE FROM feed_follows
 WHERE user_id = ?1 AND feed_id = ?2;
+
+-- name: MoveFeedFollows :exec
+UPDATE OR IGNORE feed_follows
+SET feed_id = sqlc.arg(to_feed_id)
+WHERE feed_id = sqlc.arg(from_feed_id);
+
+-- name: MoveFollowDisplayNames :exec
+UPDATE feed_follows
+SET display_name = (
+	SELECT dup.display_name
+	FROM feed_follows AS dup
+	WHERE dup.user_id = feed_follows.user_id
+	AND dup.feed_id = sqlc.arg(from_feed_id)
+)
+WHERE feed_id = sqlc.arg(to_feed_id)
+AND display_name IS NULL
+AND EXISTS (
+	SELECT 1
+	FROM feed_follows AS dup
+	WHERE dup.user_id = feed_follows.user_id
+	AND dup.feed_id = sqlc.arg(from_feed_id)
+	AND dup.display_name IS NOT NULL
+);
+
+-- name: DeleteFeedFollowsForFeed :execrows
+DELETE FROM feed_follows
+WHERE feed_id = ?1;
+
+-- name: DeleteUnstarredFeedFollowsForFeed :execrows
+DELETE FROM feed_follows
+WHERE feed_id = ?1
+AND user_id NOT IN (
+	SELECT post_stars.user_id
+	FROM post_stars
+	INNER JOIN posts
+	ON posts.id = post_stars.post_id
+	WHERE posts.feed_id = ?1
+);
+
+-- name: RenameFeedFollow :exec
+UPDATE feed_follows
+SET display_name = ?2, updated_at = ?3
+WHERE id = ?1;
+
+-- name: CountOtherFollowers :one
+SELECT COUNT(*) FROM feed_follows
+WHERE feed_id = ?1 AND user_id != ?2;
+
+-- name: DeleteFeedFollowsForUser :execrows
+DELETE FROM feed_follows
+WHERE user_id = ?1;
@@ -17,13 +17,115 @@ WHERE url = ?1;
 -- name: GetFeeds :many
 SELECT * FROM feeds;
 
+-- name: GetFeedsWithOwners :many
+SELECT sqlc.embed(feeds), users.name AS owner_name
+FROM feeds
+LEFT JOIN users
+ON users.id = feeds.user_id;
+
 -- name: MarkFeedFetched :one
 UPDATE feeds
-SET updated_at = ?2, last_fetched_at = ?2
+SET updated_at = ?2, last_fetched_at = ?2, last_attempted_at = ?2
 WHERE id = ?1
 RETURNING *;
 
+-- name: MarkFeedAttempted :exec
+UPDATE feeds
+SET last_attempted_at = ?2
+WHERE id = ?1;
+
 -- name: GetNextFeedToFetch :one
 SELECT * FROM feeds
-ORDER BY last_fetched_at IS NOT NULL, last_fetched_at
+WHERE archived_at IS NULL
+AND EXISTS (
+	SELECT 1
+	FROM feed_follows
+	WHERE feed_follows.feed_id = feeds.id
+)
+ORDER BY COALESCE(last_attempted_at, last_fetched_at) IS NOT NULL, COALESCE(last_attempted_at, last_fetched_at)
 LIMIT 1;
+
+-- name: UpdateFeedURL :one
+UPDATE feeds
+SET url = ?2, updated_at = ?3
+WHERE id = ?1
+RETURNING *;
+
+-- name: DeleteFeed :exec
+DELETE FROM feeds
+WHERE id = ?1;
+
+-- name: GetFollowedFeeds :many
+SELECT * FROM feeds
+WHERE archived_at IS NULL
+AND EXISTS (
+	SELECT 1
+	FROM feed_follows
+	WHERE feed_follows.feed_id = feeds.id
+)
+ORDER BY last_fetched_at IS NOT NULL, last_fetched_at;
+
+-- name: GetFeedsDueForFetch :many
+SELECT * FROM feeds
+WHERE archived_at IS NULL
+AND EXISTS (
+	SELECT 1
+	FROM feed_follows
+	WHERE feed_follows.feed_id = feeds.id
+)
+AND (last_fetched_at IS NULL
+OR datetime(last_fetched_at) < datetime(sqlc.arg(fetched_before)))
+ORDER BY last_fetched_at IS NOT NULL, last_fetched_at;
+
+-- name: UpdateFeedMetadata :exec
+UPDATE feeds
+SET updated_at = ?2,
+	site_link = ?3,
+	description = ?4,
+	language = ?5,
+	image_url = ?6,
+	generator = ?7,
+	last_build_date = ?8
+WHERE id = ?1;
+
+-- name: UpdateFeedName :one
+UPDATE feeds
+SET name = ?2, updated_at = ?3
+WHERE id = ?1
+RETURNING *;
+
+-- name: ArchiveFeed :exec
+UPDATE feeds
+SET archived_at = ?2, updated_at = ?2
+WHERE id = ?1;
+
+-- name: TransferFeedsOfUser :execrows
+UPDATE feeds
+SET user_id = (
+	SELECT feed_follows.user_id
+	FROM feed_follows
+	WHERE feed_follows.feed_id = feeds.id
+	AND feed_follows.user_id != sqlc.arg(user_id)
+	ORDER BY feed_follows.created_at
+	LIMIT 1
+), updated_at = sqlc.arg(updated_at)
+WHERE user_id = sqlc.arg(user_id)
+AND EXISTS (
+	SELECT 1
+	FROM feed_follows
+	WHERE feed_follows.feed_id = feeds.id
+	AND feed_follows.user_id != sqlc.arg(user_id)
+);
+
+-- name: DeleteAllFeeds :exec
+DELETE FROM feeds;
+
+-- name: GetOrphanedFeeds :many
+SELECT * FROM feeds
+WHERE NOT EXISTS (
+	SELECT 1
+	FROM feed_follows
+	WHERE feed_follows.feed_id = feeds.id
+)
+AND datetime(COALESCE(last_fetched_at, created_at)) < datetime(sqlc.arg(idle_before))
+ORDER BY COALESCE(last_fetched_at, created_at);
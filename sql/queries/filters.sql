@@ -0,0 +1,40 @@
+-- name: CreateFilter :one
+INSERT INTO filters (id, created_at, updated_at, user_id, field, match_type, pattern, action)
+VALUES (
+	?1,
+	?2,
+	?3,
+	?4,
+	?5,
+	?6,
+	?7,
+	?8
+)
+RETURNING *;
+
+-- name: GetFiltersForUser :many
+SELECT * FROM filters
+WHERE user_id = ?1 OR user_id IS NULL
+ORDER BY created_at;
+
+-- name: GetGlobalDropFilters :many
+SELECT * FROM filters
+WHERE user_id IS NULL AND action = 'drop'
+ORDER BY created_at;
+
+-- name: GetFiltersByIDPrefix :many
+SELECT * FROM filters
+WHERE id LIKE sqlc.arg(id_prefix) || '%'
+AND (user_id = sqlc.arg(user_id) OR user_id IS NULL);
+
+-- name: DeleteFilter :exec
+DELETE FROM filters
+WHERE id = ?1;
+
+-- name: DeleteFiltersForUser :execrows
+DELETE FROM filters
+WHERE user_id = ?1;
+
+-- name: DeleteGlobalFilters :exec
+DELETE FROM filters
+WHERE user_id IS NULL;
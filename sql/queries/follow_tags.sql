@@ -0,0 +1,43 @@
+-- name: AddFollowTag :execrows
+INSERT OR IGNORE INTO follow_tags (follow_id, tag, created_at)
+VALUES (
+	?1,
+	?2,
+	?3
+);
+
+-- name: MoveFollowTags :exec
+INSERT OR IGNORE INTO follow_tags (follow_id, tag, created_at)
+SELECT kept.id, follow_tags.tag, follow_tags.created_at
+FROM follow_tags
+INNER JOIN feed_follows AS dup
+ON dup.id = follow_tags.follow_id
+INNER JOIN feed_follows AS kept
+ON kept.user_id = dup.user_id
+AND kept.feed_id = sqlc.arg(to_feed_id)
+WHERE dup.feed_id = sqlc.arg(from_feed_id);
+
+-- name: RemoveFollowTag :execrows
+DELETE FROM follow_tags
+WHERE follow_id = ?1
+AND tag = ?2;
+
+-- name: GetTagsForUser :many
+SELECT follow_tags.tag,
+	COUNT(*) AS feeds_count
+FROM follow_tags
+INNER JOIN feed_follows
+ON feed_follows.id = follow_tags.follow_id
+WHERE feed_follows.user_id = ?1
+GROUP BY follow_tags.tag
+ORDER BY follow_tags.tag;
+
+-- name: GetFeedsForTag :many
+SELECT feeds.*
+FROM feeds
+INNER JOIN feed_follows
+ON feed_follows.feed_id = feeds.id
+INNER JOIN follow_tags
+ON follow_tags.follow_id = feed_follows.id
+WHERE feed_follows.user_id = ?1
+AND follow_tags.tag = ?2;
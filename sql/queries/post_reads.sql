@@ -0,0 +1,63 @@
+-- name: MarkPostRead :execrows
+INSERT OR IGNORE INTO post_reads (user_id, post_id, read_at)
+VALUES (
+	?1,
+	?2,
+	?3
+);
+
+-- name: MarkPostUnread :execrows
+DELETE FROM post_reads
+WHERE user_id = ?1
+AND post_id IN (
+	SELECT story_posts.id
+	FROM posts
+	INNER JOIN posts AS story_posts
+	ON story_posts.story_id = posts.story_id
+	WHERE posts.id = ?2
+);
+
+-- name: MarkFeedRead :execrows
+INSERT OR IGNORE INTO post_reads (user_id, post_id, read_at)
+SELECT ?1, post_sources.post_id, ?3
+FROM post_sources
+WHERE post_sources.feed_id = ?2;
+
+-- name: MarkFeedUnread :execrows
+DELETE FROM post_reads
+WHERE user_id = ?1
+AND post_id IN (
+	SELECT story_posts.id
+	FROM post_sources
+	INNER JOIN posts
+	ON posts.id = post_sources.post_id
+	INNER JOIN posts AS story_posts
+	ON story_posts.story_id = posts.story_id
+	WHERE post_sources.feed_id = ?2
+);
+
+-- name: MarkReadBefore :execrows
+INSERT OR IGNORE INTO post_reads (user_id, post_id, read_at)
+SELECT DISTINCT ?1, posts.id, ?3
+FROM posts
+INNER JOIN post_sources
+ON posts.id = post_sources.post_id
+INNER JOIN feed_follows
+ON post_sources.feed_id = feed_follows.feed_id
+WHERE feed_follows.user_id = ?1
+AND datetime(COALESCE(posts.published_at, posts.created_at)) < datetime(?2);
+
+-- name: MarkUnreadBefore :execrows
+DELETE FROM post_reads
+WHERE user_id = ?1
+AND post_id IN (
+	SELECT story_posts.id
+	FROM posts
+	INNER JOIN posts AS story_posts
+	ON story_posts.story_id = posts.story_id
+	WHERE datetime(COALESCE(posts.published_at, posts.created_at)) < datetime(?2)
+);
+
+-- name: DeletePostReadsForUser :execrows
+DELETE FROM post_reads
+WHERE user_id = ?1;
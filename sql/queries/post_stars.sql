@@ -0,0 +1,34 @@
+-- name: StarPost :execrows
+INSERT OR IGNORE INTO post_stars (user_id, post_id, starred_at)
+VALUES (
+	?1,
+	?2,
+	?3
+);
+
+-- name: UnstarPost :execrows
+DELETE FROM post_stars
+WHERE user_id = ?1
+AND post_id = ?2;
+
+-- name: GetStarredPostsForUser :many
+SELECT posts.*,
+	post_stars.starred_at,
+	json_group_array(DISTINCT COALESCE(feed_follows.display_name, feeds.name)) AS feed_names
+FROM post_stars
+INNER JOIN posts
+ON posts.id = post_stars.post_id
+INNER JOIN post_sources
+ON posts.id = post_sources.post_id
+INNER JOIN feeds
+ON feeds.id = post_sources.feed_id
+LEFT JOIN feed_follows
+ON feed_follows.feed_id = feeds.id
+AND feed_follows.user_id = post_stars.user_id
+WHERE post_stars.user_id = ?1
+GROUP BY posts.id
+ORDER BY post_stars.starred_at DESC;
+
+-- name: DeletePostStarsForUser :execrows
+DELETE FROM post_stars
+WHERE user_id = ?1;
@@ -1,5 +1,5 @@
 -- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, story_id, fingerprint, authors, content)
 VALUES (
 	?1,
 	?2,
@@ -8,22 +8,153 @@ VALUES (
 	?5,
 	?6,
 	?7,
-	?8
+	?8,
+	?9,
+	?10,
+	?11,
+	?12
 	)
 RETURNING *;
 
--- name: GetPostsForUser :many
-SELECT posts.*,
-	users.name AS user_name,
-	feeds.name AS feed_name
+-- name: GetPostByURL :one
+SELECT * FROM posts
+WHERE url = ?1;
+
+-- name: AddPostSource :exec
+INSERT OR IGNORE INTO post_sources (post_id, feed_id, created_at)
+VALUES (
+	?1,
+	?2,
+	?3
+);
+
+-- name: GetRecentFingerprints :many
+SELECT story_id, fingerprint
+FROM posts
+WHERE fingerprint IS NOT NULL
+AND story_id NOT IN (
+	SELECT story_id
+	FROM post_sources
+	WHERE feed_id = sqlc.arg(feed_id)
+)
+ORDER BY created_at DESC
+LIMIT sqlc.arg(limit);
+
+-- name: MovePosts :execrows
+UPDATE posts
+SET feed_id = sqlc.arg(to_feed_id)
+WHERE feed_id = sqlc.arg(from_feed_id);
+
+-- name: MovePostSources :exec
+UPDATE OR IGNORE post_sources
+SET feed_id = sqlc.arg(to_feed_id)
+WHERE feed_id = sqlc.arg(from_feed_id);
+
+-- name: DeletePostSourcesForFeed :exec
+DELETE FROM post_sources
+WHERE feed_id = ?1;
+
+-- name: UpdatePost :exec
+UPDATE posts
+SET updated_at = ?2, title = ?3, description = ?4, published_at = ?5, authors = ?6, content = ?7
+WHERE id = ?1;
+
+-- name: GetPostsByIDPrefix :many
+SELECT * FROM posts
+WHERE id LIKE sqlc.arg(id_prefix) || '%'
+LIMIT 2;
+
+-- name: MarkPostsPrunedBefore :exec
+INSERT OR IGNORE INTO pruned_posts (url, pruned_at)
+SELECT url, sqlc.arg(pruned_at)
 FROM posts
-INNER JOIN feed_follows
-ON posts.feed_id = feed_follows.feed_id
-INNER JOIN users
-ON feed_follows.user_id = users.id
-INNER JOIN feeds
-ON feeds.id = feed_follows.feed_id
-WHERE users.id = ?1
-AND feeds.name LIKE '%' || :feed_query || '%'
-ORDER BY published_at DESC
-LIMIT ?2;
+WHERE datetime(COALESCE(published_at, created_at)) < datetime(sqlc.arg(before))
+AND NOT EXISTS (
+	SELECT 1
+	FROM post_stars
+	WHERE post_stars.post_id = posts.id
+);
+
+-- name: IsPostPruned :one
+SELECT COUNT(*)
+FROM pruned_posts
+WHERE url = ?1;
+
+-- name: DeletePrunedPosts :exec
+DELETE FROM pruned_posts;
+
+-- name: DeletePostsBefore :execrows
+DELETE FROM posts
+WHERE datetime(COALESCE(published_at, created_at)) < datetime(sqlc.arg(before))
+AND NOT EXISTS (
+	SELECT 1
+	FROM post_stars
+	WHERE post_stars.post_id = posts.id
+);
+
+-- name: AddPostCategory :exec
+INSERT OR IGNORE INTO post_categories (post_id, category)
+VALUES (
+	?1,
+	?2
+);
+
+-- name: DeletePostCategories :exec
+DELETE FROM post_categories
+WHERE post_id = ?1;
+
+-- name: ReassignPostsOfFeed :execrows
+UPDATE posts
+SET feed_id = (
+	SELECT post_sources.feed_id
+	FROM post_sources
+	WHERE post_sources.post_id = posts.id
+	AND post_sources.feed_id != ?1
+	ORDER BY post_sources.created_at
+	LIMIT 1
+)
+WHERE feed_id = ?1
+AND EXISTS (
+	SELECT 1
+	FROM post_sources
+	WHERE post_sources.post_id = posts.id
+	AND post_sources.feed_id != ?1
+);
+
+-- name: DeletePostsOfFeed :execrows
+DELETE FROM posts
+WHERE feed_id = ?1;
+
+-- name: DeleteUnstarredPostsOfFeed :execrows
+DELETE FROM posts
+WHERE feed_id = ?1
+AND NOT EXISTS (
+	SELECT 1
+	FROM post_stars
+	WHERE post_stars.post_id = posts.id
+);
+
+-- name: DeleteSharedPostSourcesForFeed :exec
+DELETE FROM post_sources
+WHERE feed_id = ?1
+AND NOT EXISTS (
+	SELECT 1
+	FROM posts
+	WHERE posts.id = post_sources.post_id
+	AND posts.feed_id = ?1
+);
+
+-- name: CountPostsOfFeed :one
+SELECT COUNT(*)
+FROM posts
+WHERE feed_id = ?1;
+
+-- name: CountStarredPostsOfFeed :one
+SELECT COUNT(DISTINCT post_stars.post_id)
+FROM post_stars
+INNER JOIN posts
+ON posts.id = post_stars.post_id
+WHERE posts.feed_id = ?1;
+
+-- name: DeleteAllPosts :execrows
+DELETE FROM posts;
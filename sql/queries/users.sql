@@ -19,5 +19,19 @@ WHERE id = ?1;
 -- name: GetUsers :many
 SELECT * FROM users;
 
+-- name: RenameUser :one
+UPDATE users
+SET name = ?2, updated_at = ?3
+WHERE id = ?1
+RETURNING *;
+
+-- name: DeleteUser :exec
+DELETE FROM users
+WHERE id = ?1;
+
+-- name: CountFeedsOfUser :one
+SELECT COUNT(*) FROM feeds
+WHERE user_id = ?1;
+
 -- name: ResetDb :exec
 DELETE FROM users;
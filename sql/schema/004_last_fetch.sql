@@ -3,3 +3,5 @@ ALTER TABLE feeds
 ADD COLUMN last_fetched_at TIMESTAMP;
 
 -- +goose Down
+ALTER TABLE feeds
+DROP COLUMN last_fetched_at;
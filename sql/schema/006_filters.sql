@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE filters(
+	id TEXT PRIMARY KEY,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	user_id TEXT,
+	field TEXT NOT NULL,
+	match_type TEXT NOT NULL,
+	pattern TEXT NOT NULL,
+	action TEXT NOT NULL,
+	CONSTRAINT fk_users
+	FOREIGN KEY (user_id)
+	REFERENCES users(id)
+	ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE filters;
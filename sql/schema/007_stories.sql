@@ -0,0 +1,37 @@
+-- +goose Up
+ALTER TABLE posts
+ADD COLUMN story_id TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE posts
+ADD COLUMN fingerprint INTEGER;
+
+UPDATE posts
+SET story_id = id;
+
+CREATE TABLE post_sources(
+	post_id TEXT NOT NULL,
+	feed_id TEXT NOT NULL,
+	created_at DATETIME NOT NULL,
+	PRIMARY KEY (post_id, feed_id),
+	CONSTRAINT fk_posts
+	FOREIGN KEY (post_id)
+	REFERENCES posts(id)
+	ON DELETE CASCADE,
+	CONSTRAINT fk_feeds
+	FOREIGN KEY (feed_id)
+	REFERENCES feeds(id)
+	ON DELETE CASCADE
+);
+
+INSERT INTO post_sources (post_id, feed_id, created_at)
+SELECT id, feed_id, created_at
+FROM posts;
+
+-- +goose Down
+DROP TABLE post_sources;
+
+ALTER TABLE posts
+DROP COLUMN fingerprint;
+
+ALTER TABLE posts
+DROP COLUMN story_id;
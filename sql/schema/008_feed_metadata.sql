@@ -0,0 +1,37 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN site_link TEXT;
+
+ALTER TABLE feeds
+ADD COLUMN description TEXT;
+
+ALTER TABLE feeds
+ADD COLUMN language TEXT;
+
+ALTER TABLE feeds
+ADD COLUMN image_url TEXT;
+
+ALTER TABLE feeds
+ADD COLUMN generator TEXT;
+
+ALTER TABLE feeds
+ADD COLUMN last_build_date DATETIME;
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN last_build_date;
+
+ALTER TABLE feeds
+DROP COLUMN generator;
+
+ALTER TABLE feeds
+DROP COLUMN image_url;
+
+ALTER TABLE feeds
+DROP COLUMN language;
+
+ALTER TABLE feeds
+DROP COLUMN description;
+
+ALTER TABLE feeds
+DROP COLUMN site_link;
@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE post_reads(
+	user_id TEXT NOT NULL,
+	post_id TEXT NOT NULL,
+	read_at DATETIME NOT NULL,
+	PRIMARY KEY (user_id, post_id),
+	CONSTRAINT fk_users
+	FOREIGN KEY (user_id)
+	REFERENCES users(id)
+	ON DELETE CASCADE,
+	CONSTRAINT fk_posts
+	FOREIGN KEY (post_id)
+	REFERENCES posts(id)
+	ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE post_reads;
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE follow_tags(
+	follow_id TEXT NOT NULL,
+	tag TEXT NOT NULL,
+	created_at DATETIME NOT NULL,
+	PRIMARY KEY (follow_id, tag),
+	CONSTRAINT fk_feed_follows
+	FOREIGN KEY (follow_id)
+	REFERENCES feed_follows(id)
+	ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE follow_tags;
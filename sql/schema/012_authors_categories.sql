@@ -0,0 +1,22 @@
+-- +goose Up
+ALTER TABLE posts
+ADD COLUMN authors TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE post_categories(
+	post_id TEXT NOT NULL,
+	category TEXT NOT NULL,
+	PRIMARY KEY (post_id, category),
+	CONSTRAINT fk_posts
+	FOREIGN KEY (post_id)
+	REFERENCES posts(id)
+	ON DELETE CASCADE
+);
+
+CREATE INDEX idx_post_categories_category
+ON post_categories(category COLLATE NOCASE);
+
+-- +goose Down
+DROP TABLE post_categories;
+
+ALTER TABLE posts
+DROP COLUMN authors;
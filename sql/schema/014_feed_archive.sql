@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN archived_at DATETIME;
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN archived_at;
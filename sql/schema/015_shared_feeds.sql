@@ -0,0 +1,93 @@
+-- +goose Up
+-- feeds are shared by their followers, so a feed whose creator is removed stays saved without an owner,
+-- SQLite can't change constraints of a column, so the table is rebuilt, which is only safe with foreign keys off,
+-- otherwise dropping the old table would cascade onto follows and posts, gator refuses to migrate connections which have them on
+CREATE TABLE feeds_new(
+	id TEXT PRIMARY KEY,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	name TEXT NOT NULL,
+	url TEXT UNIQUE NOT NULL,
+	user_id TEXT,
+	last_fetched_at TIMESTAMP,
+	site_link TEXT,
+	description TEXT,
+	language TEXT,
+	image_url TEXT,
+	generator TEXT,
+	last_build_date DATETIME,
+	archived_at DATETIME,
+	CONSTRAINT fk_users
+	FOREIGN KEY (user_id)
+	REFERENCES users(id)
+	ON DELETE SET NULL
+);
+
+INSERT INTO feeds_new (id, created_at, updated_at, name, url, user_id, last_fetched_at, site_link, description, language, image_url, generator, last_build_date, archived_at)
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, site_link, description, language, image_url, generator, last_build_date, archived_at
+FROM feeds;
+
+DROP TABLE feeds;
+
+ALTER TABLE feeds_new
+RENAME TO feeds;
+
+-- +goose Down
+-- every feed needs an owner again, feeds without one go to their oldest follower and ones nobody follows are removed
+UPDATE feeds
+SET user_id = (
+	SELECT feed_follows.user_id
+	FROM feed_follows
+	WHERE feed_follows.feed_id = feeds.id
+	ORDER BY feed_follows.created_at
+	LIMIT 1
+)
+WHERE user_id IS NULL;
+
+DELETE FROM post_reads
+WHERE post_id IN (SELECT posts.id FROM posts INNER JOIN feeds ON feeds.id = posts.feed_id WHERE feeds.user_id IS NULL);
+
+DELETE FROM post_stars
+WHERE post_id IN (SELECT posts.id FROM posts INNER JOIN feeds ON feeds.id = posts.feed_id WHERE feeds.user_id IS NULL);
+
+DELETE FROM post_categories
+WHERE post_id IN (SELECT posts.id FROM posts INNER JOIN feeds ON feeds.id = posts.feed_id WHERE feeds.user_id IS NULL);
+
+DELETE FROM post_sources
+WHERE feed_id IN (SELECT id FROM feeds WHERE user_id IS NULL);
+
+DELETE FROM posts
+WHERE feed_id IN (SELECT id FROM feeds WHERE user_id IS NULL);
+
+DELETE FROM feeds
+WHERE user_id IS NULL;
+
+CREATE TABLE feeds_old(
+	id TEXT PRIMARY KEY,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	name TEXT NOT NULL,
+	url TEXT UNIQUE NOT NULL,
+	user_id TEXT NOT NULL,
+	last_fetched_at TIMESTAMP,
+	site_link TEXT,
+	description TEXT,
+	language TEXT,
+	image_url TEXT,
+	generator TEXT,
+	last_build_date DATETIME,
+	archived_at DATETIME,
+	CONSTRAINT fk_users
+	FOREIGN KEY (user_id)
+	REFERENCES users(id)
+	ON DELETE CASCADE
+);
+
+INSERT INTO feeds_old (id, created_at, updated_at, name, url, user_id, last_fetched_at, site_link, description, language, image_url, generator, last_build_date, archived_at)
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, site_link, description, language, image_url, generator, last_build_date, archived_at
+FROM feeds;
+
+DROP TABLE feeds;
+
+ALTER TABLE feeds_old
+RENAME TO feeds;
@@ -0,0 +1,46 @@
+-- +goose Up
+-- follows of a user are already found through the unique key on (user_id, feed_id), the rest of lookups by user or feed need indexes of their own
+CREATE INDEX idx_posts_feed_id_published_at
+ON posts(feed_id, published_at);
+
+CREATE INDEX idx_posts_story_id
+ON posts(story_id);
+
+-- browsing walks these in order of its sort keys, so the expressions have to match the ones used by browse exactly
+CREATE INDEX idx_posts_published_sort
+ON posts(datetime(COALESCE(published_at, created_at)), id);
+
+CREATE INDEX idx_posts_fetched_sort
+ON posts(datetime(created_at), id);
+
+CREATE INDEX idx_posts_recent_fingerprints
+ON posts(created_at)
+WHERE fingerprint IS NOT NULL;
+
+CREATE INDEX idx_post_sources_feed_id
+ON post_sources(feed_id, post_id);
+
+CREATE INDEX idx_feed_follows_feed_id
+ON feed_follows(feed_id);
+
+CREATE INDEX idx_feeds_user_id
+ON feeds(user_id);
+
+-- removing posts cascades onto their read and starred marks
+CREATE INDEX idx_post_reads_post_id
+ON post_reads(post_id);
+
+CREATE INDEX idx_post_stars_post_id
+ON post_stars(post_id);
+
+-- +goose Down
+DROP INDEX idx_post_stars_post_id;
+DROP INDEX idx_post_reads_post_id;
+DROP INDEX idx_feeds_user_id;
+DROP INDEX idx_feed_follows_feed_id;
+DROP INDEX idx_post_sources_feed_id;
+DROP INDEX idx_posts_recent_fingerprints;
+DROP INDEX idx_posts_fetched_sort;
+DROP INDEX idx_posts_published_sort;
+DROP INDEX idx_posts_story_id;
+DROP INDEX idx_posts_feed_id_published_at;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- links of pruned posts are remembered, so fetching feeds which still list them doesn't save them again
+CREATE TABLE pruned_posts(
+	url TEXT PRIMARY KEY,
+	pruned_at DATETIME NOT NULL
+);
+
+-- +goose Down
+DROP TABLE pruned_posts;
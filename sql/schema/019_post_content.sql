@@ -0,0 +1,12 @@
+-- +goose Up
+-- full content of items (content:encoded of RSS, content of Atom), kept besides their summary in description
+ALTER TABLE posts
+ADD COLUMN content TEXT;
+
+-- +goose Down
+-- search triggers refer to the column, they are made again by the search index of whichever build runs next
+DROP TRIGGER IF EXISTS posts_fts_insert;
+DROP TRIGGER IF EXISTS posts_fts_update;
+
+ALTER TABLE posts
+DROP COLUMN content;
@@ -0,0 +1,39 @@
+-- +goose Up
+-- sources keep the story of their post, so stories of a feed are counted from an index alone, without looking up every post
+ALTER TABLE post_sources
+ADD COLUMN story_id TEXT NOT NULL DEFAULT '';
+
+UPDATE post_sources
+SET story_id = (
+	SELECT posts.story_id
+	FROM posts
+	WHERE posts.id = post_sources.post_id
+);
+
+CREATE INDEX idx_post_sources_feed_id_story_id
+ON post_sources(feed_id, story_id);
+
+-- +goose StatementBegin
+CREATE TRIGGER post_sources_story AFTER INSERT ON post_sources BEGIN
+	UPDATE post_sources
+	SET story_id = (SELECT posts.story_id FROM posts WHERE posts.id = new.post_id)
+	WHERE post_id = new.post_id
+	AND feed_id = new.feed_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER posts_story_sources AFTER UPDATE OF story_id ON posts BEGIN
+	UPDATE post_sources
+	SET story_id = new.story_id
+	WHERE post_id = new.id;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TRIGGER posts_story_sources;
+DROP TRIGGER post_sources_story;
+DROP INDEX idx_post_sources_feed_id_story_id;
+
+ALTER TABLE post_sources
+DROP COLUMN story_id;
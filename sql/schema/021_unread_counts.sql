@@ -0,0 +1,306 @@
+-- +goose Up
+-- follows keep the number of their feed's unread stories, so listing them doesn't count every post read by the user,
+-- a story is unread when none of its posts, in any feed, is read by the follower
+ALTER TABLE feed_follows
+ADD COLUMN unread_stories INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_post_sources_story_id
+ON post_sources(story_id, feed_id);
+
+UPDATE feed_follows
+SET unread_stories = (
+	SELECT COUNT(DISTINCT post_sources.story_id)
+	FROM post_sources
+	WHERE post_sources.feed_id = feed_follows.feed_id
+	AND NOT EXISTS (
+		SELECT 1
+		FROM posts
+		CROSS JOIN post_reads
+		ON post_reads.post_id = posts.id
+		WHERE posts.story_id = post_sources.story_id
+		AND post_reads.user_id = feed_follows.user_id
+	)
+);
+
+-- +goose StatementBegin
+CREATE TRIGGER feed_follows_unread AFTER INSERT ON feed_follows BEGIN
+	UPDATE feed_follows
+	SET unread_stories = (
+		SELECT COUNT(DISTINCT post_sources.story_id)
+		FROM post_sources
+		WHERE post_sources.feed_id = new.feed_id
+		AND NOT EXISTS (
+			SELECT 1
+			FROM posts
+			CROSS JOIN post_reads
+			ON post_reads.post_id = posts.id
+			WHERE posts.story_id = post_sources.story_id
+			AND post_reads.user_id = new.user_id
+		)
+	)
+	WHERE id = new.id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER feed_follows_moved_unread AFTER UPDATE OF feed_id ON feed_follows BEGIN
+	UPDATE feed_follows
+	SET unread_stories = (
+		SELECT COUNT(DISTINCT post_sources.story_id)
+		FROM post_sources
+		WHERE post_sources.feed_id = new.feed_id
+		AND NOT EXISTS (
+			SELECT 1
+			FROM posts
+			CROSS JOIN post_reads
+			ON post_reads.post_id = posts.id
+			WHERE posts.story_id = post_sources.story_id
+			AND post_reads.user_id = new.user_id
+		)
+	)
+	WHERE id = new.id;
+END;
+-- +goose StatementEnd
+
+-- the story of a new source is taken from its post, as post_sources_story may fill it in only after this trigger
+-- +goose StatementBegin
+CREATE TRIGGER post_sources_unread AFTER INSERT ON post_sources BEGIN
+	UPDATE feed_follows
+	SET unread_stories = unread_stories + 1
+	WHERE feed_id = new.feed_id
+	AND NOT EXISTS (
+		SELECT 1
+		FROM post_sources
+		WHERE feed_id = new.feed_id
+		AND story_id = (SELECT story_id FROM posts WHERE id = new.post_id)
+		AND rowid != new.rowid
+	)
+	AND NOT EXISTS (
+		SELECT 1
+		FROM posts
+		CROSS JOIN post_reads
+		ON post_reads.post_id = posts.id
+		WHERE posts.story_id = (SELECT story_id FROM posts WHERE id = new.post_id)
+		AND post_reads.user_id = feed_follows.user_id
+	);
+END;
+-- +goose StatementEnd
+
+-- sources and reads deleted along with their post are counted by posts_unread
+-- +goose StatementBegin
+CREATE TRIGGER post_sources_deleted_unread AFTER DELETE ON post_sources
+WHEN EXISTS (SELECT 1 FROM posts WHERE id = old.post_id) BEGIN
+	UPDATE feed_follows
+	SET unread_stories = unread_stories - 1
+	WHERE feed_id = old.feed_id
+	AND NOT EXISTS (
+		SELECT 1
+		FROM post_sources
+		WHERE feed_id = old.feed_id
+		AND story_id = old.story_id
+	)
+	AND NOT EXISTS (
+		SELECT 1
+		FROM posts
+		CROSS JOIN post_reads
+		ON post_reads.post_id = posts.id
+		WHERE posts.story_id = old.story_id
+		AND post_reads.user_id = feed_follows.user_id
+	);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER post_sources_moved_unread AFTER UPDATE OF feed_id ON post_sources
+WHEN old.feed_id != new.feed_id BEGIN
+	UPDATE feed_follows
+	SET unread_stories = unread_stories - 1
+	WHERE feed_id = old.feed_id
+	AND NOT EXISTS (
+		SELECT 1
+		FROM post_sources
+		WHERE feed_id = old.feed_id
+		AND story_id = old.story_id
+	)
+	AND NOT EXISTS (
+		SELECT 1
+		FROM posts
+		CROSS JOIN post_reads
+		ON post_reads.post_id = posts.id
+		WHERE posts.story_id = old.story_id
+		AND post_reads.user_id = feed_follows.user_id
+	);
+
+	UPDATE feed_follows
+	SET unread_stories = unread_stories + 1
+	WHERE feed_id = new.feed_id
+	AND NOT EXISTS (
+		SELECT 1
+		FROM post_sources
+		WHERE feed_id = new.feed_id
+		AND story_id = new.story_id
+		AND rowid != new.rowid
+	)
+	AND NOT EXISTS (
+		SELECT 1
+		FROM posts
+		CROSS JOIN post_reads
+		ON post_reads.post_id = posts.id
+		WHERE posts.story_id = new.story_id
+		AND post_reads.user_id = feed_follows.user_id
+	);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER post_reads_unread AFTER INSERT ON post_reads BEGIN
+	UPDATE feed_follows
+	SET unread_stories = unread_stories - 1
+	WHERE user_id = new.user_id
+	AND feed_id IN (
+		SELECT feed_id
+		FROM post_sources
+		WHERE story_id = (SELECT story_id FROM posts WHERE id = new.post_id)
+	)
+	AND NOT EXISTS (
+		SELECT 1
+		FROM posts
+		CROSS JOIN post_reads
+		ON post_reads.post_id = posts.id
+		WHERE posts.story_id = (SELECT story_id FROM posts WHERE id = new.post_id)
+		AND post_reads.user_id = new.user_id
+		AND post_reads.post_id != new.post_id
+	);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER post_reads_deleted_unread AFTER DELETE ON post_reads
+WHEN EXISTS (SELECT 1 FROM posts WHERE id = old.post_id) BEGIN
+	UPDATE feed_follows
+	SET unread_stories = unread_stories + 1
+	WHERE user_id = old.user_id
+	AND feed_id IN (
+		SELECT feed_id
+		FROM post_sources
+		WHERE story_id = (SELECT story_id FROM posts WHERE id = old.post_id)
+	)
+	AND NOT EXISTS (
+		SELECT 1
+		FROM posts
+		CROSS JOIN post_reads
+		ON post_reads.post_id = posts.id
+		WHERE posts.story_id = (SELECT story_id FROM posts WHERE id = old.post_id)
+		AND post_reads.user_id = old.user_id
+	);
+END;
+-- +goose StatementEnd
+
+-- a deleted post takes its story away from feeds no other post of the story is in,
+-- and makes the story unread again for users who have read only this post of it
+-- +goose StatementBegin
+CREATE TRIGGER posts_unread BEFORE DELETE ON posts BEGIN
+	UPDATE feed_follows
+	SET unread_stories = unread_stories - 1
+	WHERE feed_id IN (
+		SELECT feed_id
+		FROM post_sources
+		WHERE post_id = old.id
+	)
+	AND NOT EXISTS (
+		SELECT 1
+		FROM post_sources
+		WHERE feed_id = feed_follows.feed_id
+		AND story_id = old.story_id
+		AND post_id != old.id
+	)
+	AND NOT EXISTS (
+		SELECT 1
+		FROM posts
+		CROSS JOIN post_reads
+		ON post_reads.post_id = posts.id
+		WHERE posts.story_id = old.story_id
+		AND post_reads.user_id = feed_follows.user_id
+	);
+
+	UPDATE feed_follows
+	SET unread_stories = unread_stories + 1
+	WHERE feed_id IN (
+		SELECT feed_id
+		FROM post_sources
+		WHERE story_id = old.story_id
+		AND post_id != old.id
+	)
+	AND EXISTS (
+		SELECT 1
+		FROM post_reads
+		WHERE user_id = feed_follows.user_id
+		AND post_id = old.id
+	)
+	AND NOT EXISTS (
+		SELECT 1
+		FROM posts
+		CROSS JOIN post_reads
+		ON post_reads.post_id = posts.id
+		WHERE posts.story_id = old.story_id
+		AND post_reads.user_id = feed_follows.user_id
+		AND post_reads.post_id != old.id
+	);
+END;
+-- +goose StatementEnd
+
+-- posts rarely change their story, so follows of both stories' feeds are counted again,
+-- which has to happen after sources get the new story, so it's done by the same trigger
+DROP TRIGGER posts_story_sources;
+
+-- +goose StatementBegin
+CREATE TRIGGER posts_story_sources AFTER UPDATE OF story_id ON posts BEGIN
+	UPDATE post_sources
+	SET story_id = new.story_id
+	WHERE post_id = new.id;
+
+	UPDATE feed_follows
+	SET unread_stories = (
+		SELECT COUNT(DISTINCT post_sources.story_id)
+		FROM post_sources
+		WHERE post_sources.feed_id = feed_follows.feed_id
+		AND NOT EXISTS (
+			SELECT 1
+			FROM posts
+			CROSS JOIN post_reads
+			ON post_reads.post_id = posts.id
+			WHERE posts.story_id = post_sources.story_id
+			AND post_reads.user_id = feed_follows.user_id
+		)
+	)
+	WHERE feed_id IN (
+		SELECT feed_id
+		FROM post_sources
+		WHERE story_id IN (old.story_id, new.story_id)
+	);
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TRIGGER posts_story_sources;
+
+-- +goose StatementBegin
+CREATE TRIGGER posts_story_sources AFTER UPDATE OF story_id ON posts BEGIN
+	UPDATE post_sources
+	SET story_id = new.story_id
+	WHERE post_id = new.id;
+END;
+-- +goose StatementEnd
+
+DROP TRIGGER posts_unread;
+DROP TRIGGER post_reads_deleted_unread;
+DROP TRIGGER post_reads_unread;
+DROP TRIGGER post_sources_moved_unread;
+DROP TRIGGER post_sources_deleted_unread;
+DROP TRIGGER post_sources_unread;
+DROP TRIGGER feed_follows_moved_unread;
+DROP TRIGGER feed_follows_unread;
+DROP INDEX idx_post_sources_story_id;
+
+ALTER TABLE feed_follows
+DROP COLUMN unread_stories;
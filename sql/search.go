@@ -0,0 +1,114 @@
+package embedding
+
+import (
+	"database/sql"
+)
+
+// searchTriggers keep the full-text index in sync with posts, whichever command writes them,
+// rows of the index share rowids with their posts, so browsing may check a single post against it
+var searchTriggers = map[string]string{
+	"posts_fts_insert": `CREATE TRIGGER posts_fts_insert AFTER INSERT ON posts BEGIN
+	INSERT INTO posts_fts (rowid, post_id, title, description, content)
+	VALUES (new.rowid, new.id, new.title, COALESCE(new.description, ''), COALESCE(new.content, ''));
+END`,
+	"posts_fts_update": `CREATE TRIGGER posts_fts_update AFTER UPDATE OF title, description, content ON posts BEGIN
+	DELETE FROM posts_fts WHERE rowid = old.rowid;
+	INSERT INTO posts_fts (rowid, post_id, title, description, content)
+	VALUES (new.rowid, new.id, new.title, COALESCE(new.description, ''), COALESCE(new.content, ''));
+END`,
+	"posts_fts_delete": `CREATE TRIGGER posts_fts_delete AFTER DELETE ON posts BEGIN
+	DELETE FROM posts_fts WHERE rowid = old.rowid;
+END`,
+}
+
+// ensureSearchIndex creates the FTS5 index of posts when SQLite was built with FTS5 (the sqlite_fts5 build tag),
+// it's kept out of migrations so the same database works with builds without FTS5, which only drop the triggers
+// and leave the index to be rebuilt by the next build which has it
+func ensureSearchIndex(db *sql.DB) error {
+	var available bool
+	if err := db.QueryRow("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&available); err != nil {
+		return err
+	}
+
+	if !available {
+		for name := range searchTriggers {
+			if _, err := db.Exec("DROP TRIGGER IF EXISTS " + name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	triggers, err := currentTriggers(db)
+	if err != nil {
+		return err
+	}
+	// indexes made before content of posts was saved lack its column, they are built anew,
+	// ones kept by older triggers, which didn't share rowids with posts, are filled anew
+	var current bool
+	err = db.QueryRow("SELECT EXISTS (SELECT 1 FROM pragma_table_info('posts_fts') WHERE name = 'content')").Scan(&current)
+	if err != nil {
+		return err
+	}
+	if current && triggers == len(searchTriggers) {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if !current {
+		if _, err := tx.Exec("DROP TABLE IF EXISTS posts_fts"); err != nil {
+			return err
+		}
+	}
+	_, err = tx.Exec("CREATE VIRTUAL TABLE IF NOT EXISTS posts_fts USING fts5(post_id UNINDEXED, title, description, content, tokenize = 'unicode61 remove_diacritics 2')")
+	if err != nil {
+		return err
+	}
+	for name, trigger := range searchTriggers {
+		if _, err := tx.Exec("DROP TRIGGER IF EXISTS " + name); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(trigger); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec("DELETE FROM posts_fts"); err != nil {
+		return err
+	}
+	_, err = tx.Exec("INSERT INTO posts_fts (rowid, post_id, title, description, content) SELECT rowid, id, title, COALESCE(description, ''), COALESCE(content, '') FROM posts")
+	if err != nil {
+		return err
+	}
+	// a single merged segment keeps lookups of single posts cheap
+	if _, err := tx.Exec("INSERT INTO posts_fts (posts_fts) VALUES ('optimize')"); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// currentTriggers counts triggers of the search index which are defined the way searchTriggers are
+func currentTriggers(db *sql.DB) (int, error) {
+	rows, err := db.Query("SELECT name, sql FROM sqlite_master WHERE type = 'trigger' AND name LIKE 'posts_fts_%'")
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	current := 0
+	for rows.Next() {
+		var name, definition string
+		if err := rows.Scan(&name, &definition); err != nil {
+			return 0, err
+		}
+		if searchTriggers[name] == definition {
+			current++
+		}
+	}
+	return current, rows.Err()
+}